@@ -0,0 +1,71 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Guardian1221/prsvc/internal/api"
+)
+
+func TestDrain(t *testing.T) {
+	h := api.NewHandler(nil)
+	started, release := make(chan struct{}), make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.Handle("/", h)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: mux}
+	go srv.Serve(ln)
+	base := "http://" + ln.Addr().String()
+
+	slow := make(chan int, 1)
+	go func() {
+		resp, err := http.Get(base + "/slow")
+		if err != nil {
+			slow <- 0
+			return
+		}
+		resp.Body.Close()
+		slow <- resp.StatusCode
+	}()
+	<-started
+
+	drained := make(chan error, 1)
+	go func() { drained <- drain(srv, h, 200*time.Millisecond, 5*time.Second) }()
+
+	// During the drain delay the server still answers, but not ready.
+	time.Sleep(50 * time.Millisecond)
+	resp, err := http.Get(base + "/readyz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("/readyz while draining: %d", resp.StatusCode)
+	}
+
+	// Shutdown waits for the in-flight request.
+	time.Sleep(300 * time.Millisecond)
+	select {
+	case err := <-drained:
+		t.Fatalf("drain returned with a request in flight: %v", err)
+	default:
+	}
+	close(release)
+	if code := <-slow; code != http.StatusOK {
+		t.Fatalf("in-flight request: %d", code)
+	}
+	if err := <-drained; err != nil {
+		t.Fatal(err)
+	}
+}
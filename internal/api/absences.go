@@ -0,0 +1,115 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+	"github.com/Guardian1221/prsvc/internal/service"
+)
+
+type addAbsenceReq struct {
+	UserID       string    `json:"user_id"`
+	StartsAt     time.Time `json:"starts_at"`
+	EndsAt       time.Time `json:"ends_at"`
+	Reason       string    `json:"reason"`
+	AutoReassign bool      `json:"auto_reassign"`
+}
+
+func (h *Handler) handleAbsenceAdd(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := h.withTimeoutContext(r)
+	defer cancel()
+
+	var req addAbsenceReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "invalid json")
+		return
+	}
+	req.UserID = pathParam(r, "user", req.UserID)
+	if req.UserID == "" {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "user_id required")
+		return
+	}
+
+	a, err := h.svc.AddAbsence(ctx, models.Absence{
+		UserID:       req.UserID,
+		StartsAt:     req.StartsAt,
+		EndsAt:       req.EndsAt,
+		Reason:       req.Reason,
+		AutoReassign: req.AutoReassign,
+	})
+	if err != nil {
+		if err == service.ErrInvalidAbsence {
+			writeErrorJSON(w, http.StatusBadRequest, "INVALID_ABSENCE", "ends_at must be after starts_at")
+			return
+		}
+		if err == sql.ErrNoRows {
+			writeErrorJSON(w, http.StatusNotFound, "NOT_FOUND", "user not found")
+			return
+		}
+		slog.ErrorContext(ctx, "AddAbsence failed", "err", err)
+		writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]any{"absence": a})
+}
+
+func (h *Handler) handleAbsenceList(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := h.withTimeoutContext(r)
+	defer cancel()
+
+	userID := pathParam(r, "user", r.URL.Query().Get("user_id"))
+	if userID == "" {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "user_id required")
+		return
+	}
+	absences, err := h.svc.ListAbsences(ctx, userID)
+	if err != nil {
+		slog.ErrorContext(ctx, "ListAbsences failed", "err", err)
+		writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"user_id": userID, "absences": absences})
+}
+
+type deleteAbsenceReq struct {
+	ID int64 `json:"id"`
+}
+
+func (h *Handler) handleAbsenceDelete(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := h.withTimeoutContext(r)
+	defer cancel()
+
+	var req deleteAbsenceReq
+	if err := decodeBody(r, &req); err != nil {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "invalid json")
+		return
+	}
+	if id := r.PathValue("id"); id != "" {
+		n, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "invalid absence id")
+			return
+		}
+		req.ID = n
+	}
+	if err := h.svc.DeleteAbsence(ctx, req.ID); err != nil {
+		if err == sql.ErrNoRows {
+			writeErrorJSON(w, http.StatusNotFound, "NOT_FOUND", "absence not found")
+			return
+		}
+		slog.ErrorContext(ctx, "DeleteAbsence failed", "err", err)
+		writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
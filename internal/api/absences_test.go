@@ -0,0 +1,134 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+)
+
+func TestAbsenceEndpoints(t *testing.T) {
+	svc, cleanup := setupTestService(t)
+	defer cleanup()
+	h := NewHandler(svc)
+
+	user := uniqueID("user")
+	w := doJSON(t, h, http.MethodPost, "/team/add", models.Team{TeamName: uniqueID("team"), Members: []models.TeamMember{{UserID: user, Username: "U", IsActive: true}}})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("/team/add: %s", w.Body)
+	}
+
+	start := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	w = doJSON(t, h, http.MethodPost, "/users/addAbsence", addAbsenceReq{UserID: user, StartsAt: start, EndsAt: start.Add(-time.Hour)})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("inverted window: %d %s", w.Code, w.Body)
+	}
+	w = doJSON(t, h, http.MethodPost, "/users/addAbsence", addAbsenceReq{UserID: uniqueID("ghost"), StartsAt: start, EndsAt: start.Add(time.Hour)})
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("unknown user: %d %s", w.Code, w.Body)
+	}
+
+	w = doJSON(t, h, http.MethodPost, "/users/"+user+"/absences", addAbsenceReq{StartsAt: start, EndsAt: start.Add(48 * time.Hour), Reason: "vacation"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("add absence: %s", w.Body)
+	}
+	var added struct {
+		Absence models.Absence `json:"absence"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&added); err != nil {
+		t.Fatal(err)
+	}
+
+	w = doJSON(t, h, http.MethodGet, "/users/getAbsences?user_id="+user, nil)
+	var listed struct {
+		Absences []models.Absence `json:"absences"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&listed); err != nil {
+		t.Fatal(err)
+	}
+	if len(listed.Absences) != 1 || listed.Absences[0].ID != added.Absence.ID || listed.Absences[0].Reason != "vacation" {
+		t.Fatalf("unexpected absences: %+v", listed.Absences)
+	}
+
+	w = doJSON(t, h, http.MethodDelete, fmt.Sprintf("/absences/%d", added.Absence.ID), nil)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("delete absence: %d %s", w.Code, w.Body)
+	}
+	w = doJSON(t, h, http.MethodPost, "/users/deleteAbsence", deleteAbsenceReq{ID: added.Absence.ID})
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("deleting twice: %d %s", w.Code, w.Body)
+	}
+}
+
+func TestAbsenceReassignsOpenReviews(t *testing.T) {
+	svc, cleanup := setupTestService(t)
+	defer cleanup()
+	h := NewHandler(svc)
+	ctx := context.Background()
+
+	author, r1, r2 := uniqueID("author"), uniqueID("r1"), uniqueID("r2")
+	w := doJSON(t, h, http.MethodPost, "/team/add", models.Team{TeamName: uniqueID("team"), MinReviewers: 1, MaxReviewers: 1, Members: []models.TeamMember{
+		{UserID: author, Username: "A", IsActive: true},
+		{UserID: r1, Username: "R1", IsActive: true},
+		{UserID: r2, Username: "R2", IsActive: true},
+	}})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("/team/add: %s", w.Body)
+	}
+
+	prID := uniqueID("pr")
+	w = doJSON(t, h, http.MethodPost, "/pullRequest/create", createPRReq{PullRequestID: prID, PullRequestName: "Absence", AuthorID: author})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("/pullRequest/create: %s", w.Body)
+	}
+	var created struct {
+		PR models.PullRequest `json:"pr"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+	if len(created.PR.AssignedReviewers) != 1 {
+		t.Fatalf("expected one reviewer, got %v", created.PR.AssignedReviewers)
+	}
+	absent, other := created.PR.AssignedReviewers[0], r1
+	if absent == r1 {
+		other = r2
+	}
+
+	now := time.Now()
+	a, err := svc.AddAbsence(ctx, models.Absence{UserID: absent, StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour), AutoReassign: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.ReassignAbsentReviewers(ctx); err != nil {
+		t.Fatalf("ReassignAbsentReviewers: %v", err)
+	}
+
+	history, err := svc.ListReassignments(ctx, prID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].OldUserID != absent || history[0].NewUserID != other || history[0].Reason != models.ReasonAbsence {
+		t.Fatalf("unexpected reassignments: %+v", history)
+	}
+
+	absences, err := svc.ListAbsences(ctx, absent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(absences) != 1 || absences[0].ID != a.ID || absences[0].ReassignedAt == nil {
+		t.Fatalf("absence should be marked handled: %+v", absences)
+	}
+
+	// A handled absence is not processed again.
+	if err := svc.ReassignAbsentReviewers(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if history, _ := svc.ListReassignments(ctx, prID); len(history) != 1 {
+		t.Fatalf("absence processed twice: %+v", history)
+	}
+}
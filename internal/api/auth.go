@@ -0,0 +1,151 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/Guardian1221/prsvc/internal/auth"
+	"github.com/Guardian1221/prsvc/internal/service"
+)
+
+// RequireAuth wraps next so that every route except the public ones needs a
+// bearer token with enough scope. The caller is available to next through
+// auth.FromContext.
+func RequireAuth(next http.Handler, a auth.Authenticator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		required, public := requiredScope(r)
+		if public {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token, ok := bearerToken(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="prsvc"`)
+			writeErrorJSON(w, http.StatusUnauthorized, "UNAUTHORIZED", "bearer token required")
+			return
+		}
+		p, err := a.Authenticate(r.Context(), token)
+		if err != nil {
+			if errors.Is(err, auth.ErrInvalidToken) {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="prsvc", error="invalid_token"`)
+				writeErrorJSON(w, http.StatusUnauthorized, "UNAUTHORIZED", "invalid or revoked token")
+				return
+			}
+			slog.ErrorContext(r.Context(), "Authenticate failed", "err", err)
+			writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
+			return
+		}
+		if !p.Scope.Allows(required) {
+			writeErrorJSON(w, http.StatusForbidden, "FORBIDDEN", "token lacks the "+string(required)+" scope")
+			return
+		}
+		markUser(r, p.Subject)
+		next.ServeHTTP(w, r.WithContext(auth.NewContext(r.Context(), p)))
+	})
+}
+
+// requiredScope returns the scope of the route serving r, see routes.
+// OPTIONS is public; a path or method no route serves needs a user token,
+// so anonymous clients cannot probe for endpoints.
+func requiredScope(r *http.Request) (auth.Scope, bool) {
+	if r.Method == http.MethodOptions {
+		return scopePublic, true
+	}
+	rt := lookupRoute(r)
+	if rt == nil {
+		return auth.ScopeUser, false
+	}
+	return rt.scope, rt.scope == scopePublic
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	h := r.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if len(h) <= len(prefix) || !strings.EqualFold(h[:len(prefix)], prefix) {
+		return "", false
+	}
+	return strings.TrimSpace(h[len(prefix):]), true
+}
+
+type issueTokenReq struct {
+	Name   string `json:"name"`
+	Scope  string `json:"scope"`
+	UserID string `json:"user_id,omitempty"`
+}
+
+func (h *Handler) handleTokenIssue(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := h.withTimeoutContext(r)
+	defer cancel()
+
+	var req issueTokenReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "invalid json")
+		return
+	}
+	token, t, err := h.svc.IssueAPIToken(ctx, req.Name, auth.Scope(req.Scope), req.UserID)
+	if err != nil {
+		if err == service.ErrInvalidTokenRequest {
+			writeErrorJSON(w, http.StatusBadRequest, "INVALID_TOKEN_REQUEST", "name required and scope must be admin or user")
+			return
+		}
+		if err == sql.ErrNoRows {
+			writeErrorJSON(w, http.StatusNotFound, "NOT_FOUND", "user not found")
+			return
+		}
+		slog.ErrorContext(ctx, "IssueAPIToken failed", "err", err)
+		writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]any{"token": token, "api_token": t})
+}
+
+type revokeTokenReq struct {
+	ID int64 `json:"id"`
+}
+
+func (h *Handler) handleTokenRevoke(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := h.withTimeoutContext(r)
+	defer cancel()
+
+	var req revokeTokenReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "invalid json")
+		return
+	}
+	t, err := h.svc.RevokeAPIToken(ctx, req.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeErrorJSON(w, http.StatusNotFound, "NOT_FOUND", "token not found")
+			return
+		}
+		slog.ErrorContext(ctx, "RevokeAPIToken failed", "err", err)
+		writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"api_token": t})
+}
+
+func (h *Handler) handleTokenList(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := h.withTimeoutContext(r)
+	defer cancel()
+
+	tokens, err := h.svc.ListAPITokens(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "ListAPITokens failed", "err", err)
+		writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"api_tokens": tokens})
+}
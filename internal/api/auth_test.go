@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Guardian1221/prsvc/internal/auth"
+)
+
+type fakeAuthenticator map[string]*auth.Principal
+
+func (f fakeAuthenticator) Authenticate(_ context.Context, token string) (*auth.Principal, error) {
+	if p, ok := f[token]; ok {
+		return p, nil
+	}
+	return nil, auth.ErrInvalidToken
+}
+
+func TestRequireAuth(t *testing.T) {
+	var seen *auth.Principal
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = auth.FromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+	h := RequireAuth(next, fakeAuthenticator{
+		"admin": {Subject: "a", Scope: auth.ScopeAdmin},
+		"user":  {Subject: "u", Scope: auth.ScopeUser},
+	})
+
+	cases := []struct {
+		method, path, token string
+		want                int
+	}{
+		{http.MethodGet, "/health", "", http.StatusOK},
+		{http.MethodGet, "/readyz", "", http.StatusOK},
+		{http.MethodPost, "/webhooks/gitlab", "", http.StatusOK},
+		{http.MethodGet, "/team/get", "", http.StatusUnauthorized},
+		{http.MethodGet, "/team/get", "bogus", http.StatusUnauthorized},
+		{http.MethodGet, "/team/get", "user", http.StatusOK},
+		{http.MethodPost, "/pullRequest/create", "user", http.StatusOK},
+		{http.MethodPost, "/team/add", "user", http.StatusForbidden},
+		{http.MethodPost, "/users/setLevel", "user", http.StatusForbidden},
+		{http.MethodGet, "/admin/tokens/list", "user", http.StatusForbidden},
+		{http.MethodPost, "/team/add", "admin", http.StatusOK},
+		{http.MethodPost, "/admin/tokens/issue", "admin", http.StatusOK},
+		{http.MethodGet, "/teams/backend", "", http.StatusUnauthorized},
+		{http.MethodGet, "/teams/backend", "user", http.StatusOK},
+		{http.MethodPost, "/pullRequests/pr-1/merge", "user", http.StatusOK},
+		{http.MethodPost, "/teams", "user", http.StatusForbidden},
+		{http.MethodDelete, "/absences/7", "user", http.StatusForbidden},
+		{http.MethodOptions, "/teams", "", http.StatusOK},
+		{http.MethodGet, "/no/such/route", "", http.StatusUnauthorized},
+	}
+	for _, c := range cases {
+		seen = nil
+		req := httptest.NewRequest(c.method, c.path, nil)
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != c.want {
+			t.Errorf("%s %s with %q: status %d, want %d", c.method, c.path, c.token, w.Code, c.want)
+		}
+		if w.Code == http.StatusOK && c.token != "" && (seen == nil || seen.Scope != auth.Scope(c.token)) {
+			t.Errorf("%s %s: principal %+v not passed on", c.method, c.path, seen)
+		}
+	}
+}
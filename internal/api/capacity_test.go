@@ -0,0 +1,116 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+)
+
+type createPRResp struct {
+	PR       models.PullRequest `json:"pr"`
+	Staffing models.Staffing    `json:"staffing"`
+}
+
+// setupCappedTeam creates a team of an author and one reviewer who takes at
+// most one open review.
+func setupCappedTeam(t *testing.T, h http.Handler) (author, reviewer string) {
+	t.Helper()
+	author, reviewer = uniqueID("author"), uniqueID("capped")
+	w := doJSON(t, h, http.MethodPost, "/team/add", models.Team{TeamName: uniqueID("team"), MinReviewers: 1, MaxReviewers: 1, Members: []models.TeamMember{
+		{UserID: author, Username: "A", IsActive: true},
+		{UserID: reviewer, Username: "R", IsActive: true},
+	}})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("/team/add: %s", w.Body)
+	}
+	one := 1
+	w = doJSON(t, h, http.MethodPost, "/users/setMaxOpenReviews", maxOpenReviewsReq{UserID: reviewer, MaxOpenReviews: &one})
+	if w.Code != http.StatusOK {
+		t.Fatalf("/users/setMaxOpenReviews: %s", w.Body)
+	}
+	return author, reviewer
+}
+
+func createPR(t *testing.T, h http.Handler, req createPRReq) createPRResp {
+	t.Helper()
+	w := doJSON(t, h, http.MethodPost, "/pullRequest/create", req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("/pullRequest/create %s: %s", req.PullRequestID, w.Body)
+	}
+	var resp createPRResp
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestReviewCapacity(t *testing.T) {
+	svc, cleanup := setupTestService(t)
+	defer cleanup()
+	h := NewHandler(svc)
+
+	author, reviewer := setupCappedTeam(t, h)
+
+	first := createPR(t, h, createPRReq{PullRequestID: uniqueID("pr"), PullRequestName: "First", AuthorID: author})
+	if len(first.PR.AssignedReviewers) != 1 || first.PR.AssignedReviewers[0] != reviewer || first.Staffing.CapacityLimited {
+		t.Fatalf("first PR should get the reviewer: %+v %+v", first.PR.AssignedReviewers, first.Staffing)
+	}
+
+	second := createPR(t, h, createPRReq{PullRequestID: uniqueID("pr"), PullRequestName: "Second", AuthorID: author})
+	if len(second.PR.AssignedReviewers) != 0 {
+		t.Fatalf("reviewer at capacity was assigned: %v", second.PR.AssignedReviewers)
+	}
+	if !second.Staffing.Understaffed || !second.Staffing.CapacityLimited {
+		t.Fatalf("expected understaffed and capacity_limited, got %+v", second.Staffing)
+	}
+
+	// An explicit request is honoured past the cap.
+	third := createPR(t, h, createPRReq{PullRequestID: uniqueID("pr"), PullRequestName: "Third", AuthorID: author, RequestedReviewers: []string{reviewer}})
+	if len(third.PR.AssignedReviewers) != 1 || third.PR.AssignedReviewers[0] != reviewer {
+		t.Fatalf("requested reviewer not assigned: %v", third.PR.AssignedReviewers)
+	}
+}
+
+func TestReviewCapacityConcurrentCreates(t *testing.T) {
+	svc, cleanup := setupTestService(t)
+	defer cleanup()
+	h := NewHandler(svc)
+
+	author, reviewer := setupCappedTeam(t, h)
+
+	const n = 8
+	prefix := uniqueID("pr")
+	results := make([]createPRResp, n)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := doJSON(t, h, http.MethodPost, "/pullRequest/create", createPRReq{PullRequestID: fmt.Sprintf("%s-%d", prefix, i), PullRequestName: "Race", AuthorID: author})
+			if w.Code != http.StatusCreated {
+				t.Errorf("/pullRequest/create: %s", w.Body)
+				return
+			}
+			if err := json.NewDecoder(w.Body).Decode(&results[i]); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assigned := 0
+	for _, res := range results {
+		for _, id := range res.PR.AssignedReviewers {
+			if id == reviewer {
+				assigned++
+			}
+		}
+	}
+	if assigned != 1 {
+		t.Fatalf("reviewer with max_open_reviews=1 assigned to %d PRs", assigned)
+	}
+}
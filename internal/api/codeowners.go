@@ -0,0 +1,67 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Guardian1221/prsvc/internal/service"
+)
+
+type codeOwnersReq struct {
+	Repository string `json:"repository"`
+	Content    string `json:"content"`
+}
+
+func (h *Handler) handleCodeOwnersSet(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := h.withTimeoutContext(r)
+	defer cancel()
+
+	var req codeOwnersReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "invalid json")
+		return
+	}
+	if req.Repository == "" {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "repository required")
+		return
+	}
+
+	if err := h.svc.SetCodeOwners(ctx, req.Repository, req.Content); err != nil {
+		if err == service.ErrInvalidCodeOwners {
+			writeErrorJSON(w, http.StatusBadRequest, "INVALID_CODEOWNERS", "CODEOWNERS could not be parsed")
+			return
+		}
+		slog.ErrorContext(ctx, "SetCodeOwners failed", "err", err)
+		writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"codeowners": req})
+}
+
+func (h *Handler) handleCodeOwnersGet(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := h.withTimeoutContext(r)
+	defer cancel()
+
+	repository := r.URL.Query().Get("repository")
+	if repository == "" {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "repository required")
+		return
+	}
+	content, err := h.svc.GetCodeOwners(ctx, repository)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeErrorJSON(w, http.StatusNotFound, "NOT_FOUND", "codeowners not found")
+			return
+		}
+		slog.ErrorContext(ctx, "GetCodeOwners failed", "err", err)
+		writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"codeowners": codeOwnersReq{Repository: repository, Content: content}})
+}
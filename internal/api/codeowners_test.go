@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+)
+
+func TestCreatePrefersCodeOwners(t *testing.T) {
+	svc, cleanup := setupTestService(t)
+	defer cleanup()
+	h := NewHandler(svc)
+
+	team, owners := uniqueID("team"), uniqueID("owners")
+	author, member, owner := uniqueID("author"), uniqueID("member"), uniqueID("owner")
+	w := doJSON(t, h, http.MethodPost, "/team/add", models.Team{TeamName: owners, Members: []models.TeamMember{
+		{UserID: owner, Username: "Owner", IsActive: true},
+	}})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("/team/add %s: %s", owners, w.Body)
+	}
+	w = doJSON(t, h, http.MethodPost, "/team/add", models.Team{TeamName: team, MinReviewers: 1, MaxReviewers: 1, Members: []models.TeamMember{
+		{UserID: author, Username: "Author", IsActive: true},
+		{UserID: member, Username: "Member", IsActive: true},
+	}})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("/team/add %s: %s", team, w.Body)
+	}
+	repository := uniqueID("repo")
+	w = doJSON(t, h, http.MethodPost, "/codeowners/set", codeOwnersReq{Repository: repository, Content: "/src/ @org/" + owner + "\n"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("/codeowners/set: %s", w.Body)
+	}
+
+	cases := []struct {
+		name       string
+		repository string
+		files      []string
+		want       string
+	}{
+		{"owned path", repository, []string{"src/main.go"}, owner},
+		{"unowned path", repository, []string{"docs/README.md"}, member},
+		{"no CODEOWNERS", uniqueID("unregistered"), []string{"src/main.go"}, member},
+	}
+	for _, c := range cases {
+		created := createPR(t, h, createPRReq{PullRequestID: uniqueID("pr"), PullRequestName: c.name, AuthorID: author, Repository: c.repository, ChangedFiles: c.files})
+		if !slices.Equal(created.PR.AssignedReviewers, []string{c.want}) {
+			t.Errorf("%s: reviewers %v, want %s", c.name, created.PR.AssignedReviewers, c.want)
+		}
+	}
+}
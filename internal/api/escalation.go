@@ -0,0 +1,74 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Guardian1221/prsvc/internal/service"
+)
+
+type escalationPolicyReq struct {
+	TeamName             string `json:"team_name"`
+	AutoEscalate         bool   `json:"auto_escalate"`
+	EscalateAfterMinutes int    `json:"escalate_after_minutes"`
+	MaxAutoReassignments int    `json:"max_auto_reassignments"`
+}
+
+func (h *Handler) handleTeamSetEscalationPolicy(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := h.withTimeoutContext(r)
+	defer cancel()
+
+	var req escalationPolicyReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "invalid json")
+		return
+	}
+	if req.TeamName == "" {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "team_name required")
+		return
+	}
+
+	t, err := h.svc.SetTeamEscalationPolicy(ctx, req.TeamName, req.AutoEscalate, req.EscalateAfterMinutes, req.MaxAutoReassignments)
+	if err != nil {
+		if err == service.ErrInvalidEscalationPolicy {
+			writeErrorJSON(w, http.StatusBadRequest, "INVALID_POLICY", "escalate_after_minutes must be positive and max_auto_reassignments not negative")
+			return
+		}
+		if err == sql.ErrNoRows {
+			writeErrorJSON(w, http.StatusNotFound, "NOT_FOUND", "team not found")
+			return
+		}
+		slog.ErrorContext(ctx, "SetTeamEscalationPolicy failed", "err", err)
+		writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"team": t})
+}
+
+func (h *Handler) handlePRReassignments(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := h.withTimeoutContext(r)
+	defer cancel()
+
+	prID := pathParam(r, "id", r.URL.Query().Get("pull_request_id"))
+	if prID == "" {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "pull_request_id required")
+		return
+	}
+	history, err := h.svc.ListReassignments(ctx, prID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeErrorJSON(w, http.StatusNotFound, "NOT_FOUND", "pr not found")
+			return
+		}
+		slog.ErrorContext(ctx, "ListReassignments failed", "err", err)
+		writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"pull_request_id": prID, "reassignments": history})
+}
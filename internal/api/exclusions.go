@@ -0,0 +1,104 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+	"github.com/Guardian1221/prsvc/internal/service"
+)
+
+type exclusionReq struct {
+	TeamName   string `json:"team_name"`
+	AuthorID   string `json:"author_id"`
+	ReviewerID string `json:"reviewer_id"`
+}
+
+func decodeExclusion(w http.ResponseWriter, r *http.Request) (models.ReviewerExclusion, bool) {
+	var req exclusionReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "invalid json")
+		return models.ReviewerExclusion{}, false
+	}
+	if req.TeamName == "" || req.AuthorID == "" || req.ReviewerID == "" {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "team_name, author_id and reviewer_id required")
+		return models.ReviewerExclusion{}, false
+	}
+	return models.ReviewerExclusion{TeamName: req.TeamName, AuthorID: req.AuthorID, ReviewerID: req.ReviewerID}, true
+}
+
+func (h *Handler) handleTeamAddExclusion(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := h.withTimeoutContext(r)
+	defer cancel()
+
+	x, ok := decodeExclusion(w, r)
+	if !ok {
+		return
+	}
+	exclusions, err := h.svc.AddReviewerExclusion(ctx, x)
+	if err != nil {
+		if err == service.ErrInvalidExclusion {
+			writeErrorJSON(w, http.StatusBadRequest, "INVALID_EXCLUSION", "author must be a member of the team and differ from the reviewer")
+			return
+		}
+		if err == sql.ErrNoRows {
+			writeErrorJSON(w, http.StatusNotFound, "NOT_FOUND", "user not found")
+			return
+		}
+		slog.ErrorContext(ctx, "AddReviewerExclusion failed", "err", err)
+		writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"team_name": x.TeamName, "exclusions": exclusions})
+}
+
+func (h *Handler) handleTeamDeleteExclusion(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := h.withTimeoutContext(r)
+	defer cancel()
+
+	x, ok := decodeExclusion(w, r)
+	if !ok {
+		return
+	}
+	exclusions, err := h.svc.DeleteReviewerExclusion(ctx, x)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeErrorJSON(w, http.StatusNotFound, "NOT_FOUND", "exclusion not found")
+			return
+		}
+		slog.ErrorContext(ctx, "DeleteReviewerExclusion failed", "err", err)
+		writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"team_name": x.TeamName, "exclusions": exclusions})
+}
+
+func (h *Handler) handleTeamGetExclusions(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := h.withTimeoutContext(r)
+	defer cancel()
+
+	teamName := pathParam(r, "team", r.URL.Query().Get("team_name"))
+	if teamName == "" {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "team_name required")
+		return
+	}
+	exclusions, err := h.svc.ListReviewerExclusions(ctx, teamName)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeErrorJSON(w, http.StatusNotFound, "NOT_FOUND", "team not found")
+			return
+		}
+		slog.ErrorContext(ctx, "ListReviewerExclusions failed", "err", err)
+		writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"team_name": teamName, "exclusions": exclusions})
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+)
+
+// setupExclusionTeam creates a team of the author and two reviewers, plus
+// an outsider in another team.
+func setupExclusionTeam(t *testing.T, h http.Handler, maxReviewers int) (team, author, r1, r2, outsider string) {
+	t.Helper()
+	team, author = uniqueID("team"), uniqueID("author")
+	r1, r2, outsider = uniqueID("r1"), uniqueID("r2"), uniqueID("outsider")
+	w := doJSON(t, h, http.MethodPost, "/team/add", models.Team{TeamName: team, MinReviewers: 1, MaxReviewers: maxReviewers, Members: []models.TeamMember{
+		{UserID: author, Username: "Author", IsActive: true},
+		{UserID: r1, Username: "R1", IsActive: true},
+		{UserID: r2, Username: "R2", IsActive: true},
+	}})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("/team/add %s: %s", team, w.Body)
+	}
+	other := uniqueID("other")
+	w = doJSON(t, h, http.MethodPost, "/team/add", models.Team{TeamName: other, Members: []models.TeamMember{
+		{UserID: outsider, Username: "Outsider", IsActive: true},
+	}})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("/team/add %s: %s", other, w.Body)
+	}
+	return team, author, r1, r2, outsider
+}
+
+func TestExclusionPairs(t *testing.T) {
+	svc, cleanup := setupTestService(t)
+	defer cleanup()
+	h := NewHandler(svc)
+
+	team, author, r1, r2, _ := setupExclusionTeam(t, h, 2)
+	w := doJSON(t, h, http.MethodPost, "/team/addExclusion", exclusionReq{TeamName: team, AuthorID: author, ReviewerID: r1})
+	if w.Code != http.StatusOK {
+		t.Fatalf("/team/addExclusion: %s", w.Body)
+	}
+
+	created := createPR(t, h, createPRReq{PullRequestID: uniqueID("pr"), PullRequestName: "Pairs", AuthorID: author})
+	if !slices.Equal(created.PR.AssignedReviewers, []string{r2}) {
+		t.Fatalf("excluded reviewer picked: %v", created.PR.AssignedReviewers)
+	}
+
+	w = doJSON(t, h, http.MethodPost, "/pullRequest/create", createPRReq{PullRequestID: uniqueID("pr"), PullRequestName: "Pairs", AuthorID: author, RequestedReviewers: []string{r1}})
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "INVALID_REVIEWER") {
+		t.Fatalf("requesting an excluded reviewer: %d %s", w.Code, w.Body)
+	}
+}
+
+func TestRequestedReviewersValidation(t *testing.T) {
+	svc, cleanup := setupTestService(t)
+	defer cleanup()
+	h := NewHandler(svc)
+
+	_, author, r1, r2, outsider := setupExclusionTeam(t, h, 1)
+	cases := map[string]createPRReq{
+		"author":             {RequestedReviewers: []string{author}},
+		"unknown user":       {RequestedReviewers: []string{uniqueID("ghost")}},
+		"other team":         {RequestedReviewers: []string{outsider}},
+		"duplicate":          {RequestedReviewers: []string{r1, r1}},
+		"also excluded":      {RequestedReviewers: []string{r1}, ExcludedReviewers: []string{r1}},
+		"over max_reviewers": {RequestedReviewers: []string{r1, r2}},
+	}
+	for name, req := range cases {
+		req.PullRequestID, req.PullRequestName, req.AuthorID = uniqueID("pr"), "Requested", author
+		w := doJSON(t, h, http.MethodPost, "/pullRequest/create", req)
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "INVALID_REVIEWER") {
+			t.Errorf("%s: %d %s", name, w.Code, w.Body)
+		}
+	}
+}
+
+func TestExcludedReviewersKeptAfterCreate(t *testing.T) {
+	svc, cleanup := setupTestService(t)
+	defer cleanup()
+	h := NewHandler(svc)
+
+	_, author, r1, r2, _ := setupExclusionTeam(t, h, 2)
+
+	prID := uniqueID("pr")
+	created := createPR(t, h, createPRReq{PullRequestID: prID, PullRequestName: "Excluded", AuthorID: author, ExcludedReviewers: []string{r2}})
+	if !slices.Equal(created.PR.AssignedReviewers, []string{r1}) {
+		t.Fatalf("expected only %s, got %v", r1, created.PR.AssignedReviewers)
+	}
+	w := doJSON(t, h, http.MethodPost, "/pullRequest/reassign", reassignReq{PullRequestID: prID, OldUserID: r1})
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "NO_CANDIDATE") {
+		t.Fatalf("reassign picked an excluded reviewer: %d %s", w.Code, w.Body)
+	}
+
+	draftID := uniqueID("draft")
+	createPR(t, h, createPRReq{PullRequestID: draftID, PullRequestName: "Excluded", AuthorID: author, Draft: true, ExcludedReviewers: []string{r2}})
+	w = doJSON(t, h, http.MethodPost, "/pullRequest/ready", prActionReq{PullRequestID: draftID})
+	if w.Code != http.StatusOK {
+		t.Fatalf("/pullRequest/ready: %s", w.Body)
+	}
+	var ready struct {
+		PR models.PullRequest `json:"pr"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&ready); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(ready.PR.AssignedReviewers, []string{r1}) {
+		t.Fatalf("ready assigned %v, want only %s", ready.PR.AssignedReviewers, r1)
+	}
+}
+
+func TestDraftRequestedReviewers(t *testing.T) {
+	svc, cleanup := setupTestService(t)
+	defer cleanup()
+	h := NewHandler(svc)
+
+	_, author, _, r2, outsider := setupExclusionTeam(t, h, 1)
+	w := doJSON(t, h, http.MethodPost, "/pullRequest/create", createPRReq{PullRequestID: uniqueID("draft"), PullRequestName: "Requested", AuthorID: author, Draft: true, RequestedReviewers: []string{outsider}})
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "INVALID_REVIEWER") {
+		t.Fatalf("draft requesting an outsider: %d %s", w.Code, w.Body)
+	}
+
+	draftID := uniqueID("draft")
+	draft := createPR(t, h, createPRReq{PullRequestID: draftID, PullRequestName: "Requested", AuthorID: author, Draft: true, RequestedReviewers: []string{r2}})
+	if len(draft.PR.AssignedReviewers) != 0 {
+		t.Fatalf("draft assigned %v", draft.PR.AssignedReviewers)
+	}
+	ready := decodePR(t, doJSON(t, h, http.MethodPost, "/pullRequest/ready", prActionReq{PullRequestID: draftID}))
+	if !slices.Equal(ready.AssignedReviewers, []string{r2}) {
+		t.Fatalf("ready assigned %v, want the requested %s", ready.AssignedReviewers, r2)
+	}
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+)
+
+// setupFallbackTeams creates a team whose only reviewer besides the author
+// is busy being the first pick, with a two-member fallback team.
+func setupFallbackTeams(t *testing.T, h http.Handler) (author, member string, fallbackMembers []string) {
+	t.Helper()
+	team, fallback := uniqueID("team"), uniqueID("fallback")
+	author, member = uniqueID("author"), uniqueID("member")
+	fallbackMembers = []string{uniqueID("fb1"), uniqueID("fb2")}
+
+	w := doJSON(t, h, http.MethodPost, "/team/add", models.Team{TeamName: fallback, Members: []models.TeamMember{
+		{UserID: fallbackMembers[0], Username: "F1", IsActive: true},
+		{UserID: fallbackMembers[1], Username: "F2", IsActive: true},
+	}})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("/team/add %s: %s", fallback, w.Body)
+	}
+	w = doJSON(t, h, http.MethodPost, "/team/add", models.Team{TeamName: team, MinReviewers: 2, MaxReviewers: 2, Members: []models.TeamMember{
+		{UserID: author, Username: "Author", IsActive: true},
+		{UserID: member, Username: "Member", IsActive: true},
+	}})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("/team/add %s: %s", team, w.Body)
+	}
+	w = doJSON(t, h, http.MethodPost, "/team/setFallbacks", fallbacksReq{TeamName: team, FallbackTeams: []string{fallback}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("/team/setFallbacks: %s", w.Body)
+	}
+	return author, member, fallbackMembers
+}
+
+func TestCreateBorrowsFromFallbackTeam(t *testing.T) {
+	svc, cleanup := setupTestService(t)
+	defer cleanup()
+	h := NewHandler(svc)
+
+	author, member, fallbackMembers := setupFallbackTeams(t, h)
+	w := doJSON(t, h, http.MethodPost, "/pullRequest/create", createPRReq{PullRequestID: uniqueID("pr"), PullRequestName: "Fallback", AuthorID: author})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("/pullRequest/create: %s", w.Body)
+	}
+	var resp struct {
+		PR       models.PullRequest `json:"pr"`
+		Staffing models.Staffing    `json:"staffing"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resp.PR.AssignedReviewers) != 2 || resp.Staffing.Understaffed {
+		t.Fatalf("expected two reviewers, got %+v %+v", resp.PR.AssignedReviewers, resp.Staffing)
+	}
+	if !slices.Contains(resp.PR.AssignedReviewers, member) {
+		t.Fatalf("own team member should be picked first: %v", resp.PR.AssignedReviewers)
+	}
+	if len(resp.Staffing.FallbackReviewers) != 1 || !slices.Contains(fallbackMembers, resp.Staffing.FallbackReviewers[0]) {
+		t.Fatalf("expected one reviewer borrowed from the fallback team, got %v", resp.Staffing.FallbackReviewers)
+	}
+}
+
+func TestReassignUsesAuthorTeamFallbacks(t *testing.T) {
+	svc, cleanup := setupTestService(t)
+	defer cleanup()
+	h := NewHandler(svc)
+
+	author, _, fallbackMembers := setupFallbackTeams(t, h)
+	prID := uniqueID("pr")
+	w := doJSON(t, h, http.MethodPost, "/pullRequest/create", createPRReq{PullRequestID: prID, PullRequestName: "Fallback", AuthorID: author})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("/pullRequest/create: %s", w.Body)
+	}
+	var created struct {
+		Staffing models.Staffing `json:"staffing"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+	if len(created.Staffing.FallbackReviewers) != 1 {
+		t.Fatalf("expected one fallback reviewer, got %+v", created.Staffing)
+	}
+	borrowed := created.Staffing.FallbackReviewers[0]
+
+	// The author's team has no one left, so the borrowed reviewer's
+	// replacement must again come from the author's fallback team.
+	w = doJSON(t, h, http.MethodPost, "/pullRequest/reassign", reassignReq{PullRequestID: prID, OldUserID: borrowed})
+	if w.Code != http.StatusOK {
+		t.Fatalf("/pullRequest/reassign: %s", w.Body)
+	}
+	var res struct {
+		ReplacedBy   string `json:"replaced_by"`
+		FromFallback bool   `json:"from_fallback"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+	if res.ReplacedBy == borrowed || !slices.Contains(fallbackMembers, res.ReplacedBy) || !res.FromFallback {
+		t.Fatalf("expected the other fallback member with from_fallback, got %+v", res)
+	}
+}
@@ -2,53 +2,83 @@ package api
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/Guardian1221/prsvc/internal/models"
 	"github.com/Guardian1221/prsvc/internal/repo"
 	"github.com/Guardian1221/prsvc/internal/service"
+	"github.com/Guardian1221/prsvc/internal/vcs"
 )
 
 type Handler struct {
 	svc *service.Service
+
+	vcs          *vcs.Processor
+	gitlabSecret string
+
+	mux *http.ServeMux
+
+	timeout          time.Duration
+	readinessTimeout time.Duration
+
+	draining atomic.Bool
 }
 
-func NewHandler(svc *service.Service) *Handler {
-	return &Handler{svc: svc}
+// DefaultRequestTimeout bounds the service calls of a single request.
+const DefaultRequestTimeout = 5 * time.Second
+
+type Option func(*Handler)
+
+// WithGitLabWebhook enables POST /webhooks/gitlab, accepting deliveries whose
+// X-Gitlab-Token matches secret.
+func WithGitLabWebhook(secret string) Option {
+	return func(h *Handler) {
+		h.gitlabSecret = secret
+	}
 }
 
-func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	switch {
-	case r.Method == http.MethodPost && r.URL.Path == "/team/add":
-		h.handleTeamAdd(w, r)
-	case r.Method == http.MethodGet && r.URL.Path == "/team/get":
-		h.handleTeamGet(w, r)
-	case r.Method == http.MethodPost && r.URL.Path == "/pullRequest/create":
-		h.handlePRCreate(w, r)
-	case r.Method == http.MethodPost && r.URL.Path == "/pullRequest/reassign":
-		h.handlePRReassign(w, r)
-	case r.Method == http.MethodGet && r.URL.Path == "/health":
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(`{"status":"ok"}`))
-	case r.Method == http.MethodGet && r.URL.Path == "/":
-		w.WriteHeader(http.StatusOK)
-	default:
-		http.NotFound(w, r)
+// WithRequestTimeout replaces DefaultRequestTimeout.
+func WithRequestTimeout(d time.Duration) Option {
+	return func(h *Handler) {
+		h.timeout = d
 	}
 }
 
-func withTimeoutContext(r *http.Request) (context.Context, context.CancelFunc) {
-	timeout := 5 * time.Second
+// WithReadinessTimeout replaces service.DefaultReadinessTimeout for the
+// checks behind /readyz.
+func WithReadinessTimeout(d time.Duration) Option {
+	return func(h *Handler) {
+		h.readinessTimeout = d
+	}
+}
+
+func NewHandler(svc *service.Service, opts ...Option) *Handler {
+	h := &Handler{
+		svc:              svc,
+		vcs:              vcs.NewProcessor(svc),
+		timeout:          DefaultRequestTimeout,
+		readinessTimeout: service.DefaultReadinessTimeout,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.mux = h.newMux()
+	return h
+}
+
+func (h *Handler) withTimeoutContext(r *http.Request) (context.Context, context.CancelFunc) {
 	ctx := r.Context()
-	return context.WithTimeout(ctx, timeout)
+	return context.WithTimeout(ctx, h.timeout)
 }
 
 func (h *Handler) handleTeamAdd(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := withTimeoutContext(r)
+	ctx, cancel := h.withTimeoutContext(r)
 	defer cancel()
 
 	body, err := io.ReadAll(r.Body)
@@ -72,21 +102,30 @@ func (h *Handler) handleTeamAdd(w http.ResponseWriter, r *http.Request) {
 			writeErrorJSON(w, http.StatusBadRequest, "TEAM_EXISTS", "team_name already exists")
 			return
 		}
-		log.Printf("CreateTeam error: %v", err)
+		if err == service.ErrInvalidReviewerPolicy {
+			writeErrorJSON(w, http.StatusBadRequest, "INVALID_POLICY", "min_reviewers and max_reviewers are out of range")
+			return
+		}
+		slog.ErrorContext(ctx, "CreateTeam failed", "err", err)
 		writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
 		return
 	}
 
+	// Reload so the response shows the reviewer policy actually stored.
+	if created, err := h.svc.GetTeam(ctx, t.TeamName); err == nil {
+		t = *created
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(map[string]any{"team": t})
 }
 
 func (h *Handler) handleTeamGet(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := withTimeoutContext(r)
+	ctx, cancel := h.withTimeoutContext(r)
 	defer cancel()
 
-	q := r.URL.Query().Get("team_name")
+	q := pathParam(r, "team", r.URL.Query().Get("team_name"))
 	if q == "" {
 		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "team_name required")
 		return
@@ -100,14 +139,97 @@ func (h *Handler) handleTeamGet(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(t)
 }
 
+type reviewerPolicyReq struct {
+	TeamName     string `json:"team_name"`
+	MinReviewers int    `json:"min_reviewers"`
+	MaxReviewers int    `json:"max_reviewers"`
+}
+
+func (h *Handler) handleTeamSetReviewerPolicy(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := h.withTimeoutContext(r)
+	defer cancel()
+
+	var req reviewerPolicyReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "invalid json")
+		return
+	}
+	if req.TeamName == "" {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "team_name required")
+		return
+	}
+
+	t, err := h.svc.SetTeamReviewerPolicy(ctx, req.TeamName, req.MinReviewers, req.MaxReviewers)
+	if err != nil {
+		if err == service.ErrInvalidReviewerPolicy {
+			writeErrorJSON(w, http.StatusBadRequest, "INVALID_POLICY", "min_reviewers and max_reviewers are out of range")
+			return
+		}
+		if err == sql.ErrNoRows {
+			writeErrorJSON(w, http.StatusNotFound, "NOT_FOUND", "team not found")
+			return
+		}
+		slog.ErrorContext(ctx, "SetTeamReviewerPolicy failed", "err", err)
+		writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"team": t})
+}
+
+type fallbacksReq struct {
+	TeamName      string   `json:"team_name"`
+	FallbackTeams []string `json:"fallback_teams"`
+}
+
+func (h *Handler) handleTeamSetFallbacks(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := h.withTimeoutContext(r)
+	defer cancel()
+
+	var req fallbacksReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "invalid json")
+		return
+	}
+	if req.TeamName == "" {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "team_name required")
+		return
+	}
+
+	t, err := h.svc.SetTeamFallbacks(ctx, req.TeamName, req.FallbackTeams)
+	if err != nil {
+		if err == repo.ErrInvalidFallback {
+			writeErrorJSON(w, http.StatusBadRequest, "INVALID_FALLBACK", "fallback teams must be existing, distinct teams other than team_name")
+			return
+		}
+		if err == sql.ErrNoRows {
+			writeErrorJSON(w, http.StatusNotFound, "NOT_FOUND", "team not found")
+			return
+		}
+		slog.ErrorContext(ctx, "SetTeamFallbacks failed", "err", err)
+		writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"team": t})
+}
+
 type createPRReq struct {
-	PullRequestID   string `json:"pull_request_id"`
-	PullRequestName string `json:"pull_request_name"`
-	AuthorID        string `json:"author_id"`
+	PullRequestID   string   `json:"pull_request_id"`
+	PullRequestName string   `json:"pull_request_name"`
+	AuthorID        string   `json:"author_id"`
+	Repository      string   `json:"repository,omitempty"`
+	ChangedFiles    []string `json:"changed_files,omitempty"`
+	Draft           bool     `json:"draft,omitempty"`
+
+	RequestedReviewers []string `json:"requested_reviewers,omitempty"`
+	ExcludedReviewers  []string `json:"excluded_reviewers,omitempty"`
 }
 
 func (h *Handler) handlePRCreate(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := withTimeoutContext(r)
+	ctx, cancel := h.withTimeoutContext(r)
 	defer cancel()
 
 	var req createPRReq
@@ -119,18 +241,33 @@ func (h *Handler) handlePRCreate(w http.ResponseWriter, r *http.Request) {
 		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "missing fields")
 		return
 	}
+	if len(req.ChangedFiles) > 0 && req.Repository == "" {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "repository required with changed_files")
+		return
+	}
 
 	pr := models.PullRequest{
 		PullRequestID:   req.PullRequestID,
 		PullRequestName: req.PullRequestName,
 		AuthorID:        req.AuthorID,
 	}
-	created, err := h.svc.CreatePullRequest(ctx, pr)
+	created, staffing, err := h.svc.CreatePullRequest(ctx, pr, service.CreateOptions{
+		Repository:   req.Repository,
+		ChangedFiles: req.ChangedFiles,
+		Draft:        req.Draft,
+
+		RequestedReviewers: req.RequestedReviewers,
+		ExcludedReviewers:  req.ExcludedReviewers,
+	})
 	if err != nil {
 		if err == repo.ErrPRExists {
 			writeErrorJSON(w, http.StatusConflict, "PR_EXISTS", "PR id already exists")
 			return
 		}
+		if err == service.ErrInvalidRequestedReviewer {
+			writeErrorJSON(w, http.StatusBadRequest, "INVALID_REVIEWER", "requested reviewers must be active members of the author's team, not the author and not excluded")
+			return
+		}
 		if err == nil {
 		}
 		writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", err.Error())
@@ -139,7 +276,7 @@ func (h *Handler) handlePRCreate(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]any{"pr": created})
+	json.NewEncoder(w).Encode(map[string]any{"pr": created, "staffing": staffing})
 }
 
 type reassignReq struct {
@@ -148,7 +285,7 @@ type reassignReq struct {
 }
 
 func (h *Handler) handlePRReassign(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := withTimeoutContext(r)
+	ctx, cancel := h.withTimeoutContext(r)
 	defer cancel()
 
 	var req reassignReq
@@ -156,17 +293,35 @@ func (h *Handler) handlePRReassign(w http.ResponseWriter, r *http.Request) {
 		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "invalid json")
 		return
 	}
+	req.PullRequestID = pathParam(r, "id", req.PullRequestID)
 	if req.PullRequestID == "" || req.OldUserID == "" {
 		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "missing fields")
 		return
 	}
 
-	newID, pr, err := h.svc.ReassignReviewer(ctx, req.PullRequestID, req.OldUserID)
+	if err := h.svc.AuthorizePRAuthor(ctx, req.PullRequestID); err != nil {
+		if err == service.ErrForbidden {
+			writeErrorJSON(w, http.StatusForbidden, "FORBIDDEN", "only the PR author or an admin can reassign")
+			return
+		}
+		if err == sql.ErrNoRows {
+			writeErrorJSON(w, http.StatusNotFound, "NOT_FOUND", "pr or user not found")
+			return
+		}
+		slog.ErrorContext(ctx, "AuthorizePRAuthor failed", "err", err)
+		writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
+		return
+	}
+
+	res, err := h.svc.ReassignReviewer(ctx, req.PullRequestID, req.OldUserID, models.ReasonManual)
 	if err != nil {
 		switch err {
 		case repo.ErrPRMerged:
 			writeErrorJSON(w, http.StatusConflict, "PR_MERGED", "cannot reassign on merged PR")
 			return
+		case repo.ErrPRNotOpen:
+			writeErrorJSON(w, http.StatusConflict, "PR_NOT_OPEN", "cannot reassign on a draft or closed PR")
+			return
 		case repo.ErrNotAssigned:
 			writeErrorJSON(w, http.StatusConflict, "NOT_ASSIGNED", "reviewer is not assigned to this PR")
 			return
@@ -178,12 +333,12 @@ func (h *Handler) handlePRReassign(w http.ResponseWriter, r *http.Request) {
 				writeErrorJSON(w, http.StatusNotFound, "NOT_FOUND", "pr or user not found")
 				return
 			}
-			log.Printf("reassign error: %v", err)
+			slog.ErrorContext(ctx, "reassign failed", "err", err)
 			writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
 			return
 		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]any{"pr": pr, "replaced_by": newID})
+	json.NewEncoder(w).Encode(map[string]any{"pr": res.PR, "replaced_by": res.ReplacedBy, "from_fallback": res.FromFallback, "level_unmet": res.LevelUnmet})
 }
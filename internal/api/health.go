@@ -0,0 +1,43 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+)
+
+// handleLivez only tells that the process serves HTTP; restarting it would
+// not fix a broken dependency.
+func (h *Handler) handleLivez(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(`{"status":"ok"}`))
+}
+
+// SetDraining makes /readyz fail while the server shuts down, so traffic
+// moves away before connections are closed.
+func (h *Handler) SetDraining(draining bool) {
+	h.draining.Store(draining)
+}
+
+// handleReadyz answers 503 while any dependency check fails or the server
+// is draining, so the instance is taken out of rotation.
+func (h *Handler) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if h.draining.Load() {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(models.Readiness{
+			Status: models.CheckFail,
+			Checks: map[string]models.CheckResult{"shutdown": {Status: models.CheckFail, Error: "draining"}},
+		})
+		return
+	}
+	res := h.svc.CheckReadiness(r.Context(), h.readinessTimeout)
+
+	w.Header().Set("Content-Type", "application/json")
+	if res.Status != models.CheckOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(res)
+}
@@ -11,13 +11,15 @@ type ErrorDetail struct {
 }
 
 type ErrorResponse struct {
-    Error ErrorDetail `json:"error"`
+    Error     ErrorDetail `json:"error"`
+    RequestID string      `json:"request_id,omitempty"`
 }
 
 func writeErrorJSON(w http.ResponseWriter, status int, code, message string) {
     w.Header().Set("Content-Type", "application/json")
     w.WriteHeader(status)
     _ = json.NewEncoder(w).Encode(ErrorResponse{
-        Error: ErrorDetail{Code: code, Message: message},
+        Error:     ErrorDetail{Code: code, Message: message},
+        RequestID: w.Header().Get(RequestIDHeader),
     })
 }
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Guardian1221/prsvc/internal/service"
+)
+
+type userLevelReq struct {
+	UserID string `json:"user_id"`
+	Level  string `json:"level"`
+}
+
+func (h *Handler) handleUserSetLevel(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := h.withTimeoutContext(r)
+	defer cancel()
+
+	var req userLevelReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "invalid json")
+		return
+	}
+	if req.UserID == "" {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "user_id required")
+		return
+	}
+
+	u, err := h.svc.SetUserLevel(ctx, req.UserID, req.Level)
+	if err != nil {
+		if err == service.ErrInvalidLevel {
+			writeErrorJSON(w, http.StatusBadRequest, "INVALID_LEVEL", "level must be JUNIOR, MID or SENIOR")
+			return
+		}
+		if err == sql.ErrNoRows {
+			writeErrorJSON(w, http.StatusNotFound, "NOT_FOUND", "user not found")
+			return
+		}
+		slog.ErrorContext(ctx, "SetUserLevel failed", "err", err)
+		writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"user": newUserView(u)})
+}
+
+type levelPolicyReq struct {
+	TeamName           string `json:"team_name"`
+	RequiredLevel      string `json:"required_level"`
+	RequiredLevelCount int    `json:"required_level_count"`
+}
+
+func (h *Handler) handleTeamSetLevelPolicy(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := h.withTimeoutContext(r)
+	defer cancel()
+
+	var req levelPolicyReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "invalid json")
+		return
+	}
+	if req.TeamName == "" {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "team_name required")
+		return
+	}
+
+	t, err := h.svc.SetTeamLevelPolicy(ctx, req.TeamName, req.RequiredLevel, req.RequiredLevelCount)
+	if err != nil {
+		if err == service.ErrInvalidLevelPolicy {
+			writeErrorJSON(w, http.StatusBadRequest, "INVALID_POLICY", "required_level must be JUNIOR, MID or SENIOR and required_level_count between 0 and 10")
+			return
+		}
+		if err == sql.ErrNoRows {
+			writeErrorJSON(w, http.StatusNotFound, "NOT_FOUND", "team not found")
+			return
+		}
+		slog.ErrorContext(ctx, "SetTeamLevelPolicy failed", "err", err)
+		writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"team": t})
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+)
+
+// setupLevelTeam creates a team of the author, seniors and juniors that
+// needs required reviewers at SENIOR out of maxReviewers.
+func setupLevelTeam(t *testing.T, h http.Handler, seniors, juniors, required, maxReviewers int) (team, author string, seniorIDs []string) {
+	t.Helper()
+	team, author = uniqueID("team"), uniqueID("author")
+	members := []models.TeamMember{{UserID: author, Username: "Author", IsActive: true}}
+	levels := map[string]string{}
+	for i := 0; i < seniors+juniors; i++ {
+		id, level := uniqueID(fmt.Sprintf("senior%d", i)), models.LevelSenior
+		if i >= seniors {
+			id, level = uniqueID(fmt.Sprintf("junior%d", i)), models.LevelJunior
+		} else {
+			seniorIDs = append(seniorIDs, id)
+		}
+		members = append(members, models.TeamMember{UserID: id, Username: id, IsActive: true})
+		levels[id] = level
+	}
+	w := doJSON(t, h, http.MethodPost, "/team/add", models.Team{TeamName: team, MinReviewers: 1, MaxReviewers: maxReviewers, Members: members})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("/team/add: %s", w.Body)
+	}
+	for id, level := range levels {
+		w = doJSON(t, h, http.MethodPost, "/users/setLevel", userLevelReq{UserID: id, Level: level})
+		if w.Code != http.StatusOK {
+			t.Fatalf("/users/setLevel %s: %s", id, w.Body)
+		}
+	}
+	w = doJSON(t, h, http.MethodPost, "/team/setLevelPolicy", levelPolicyReq{TeamName: team, RequiredLevel: models.LevelSenior, RequiredLevelCount: required})
+	if w.Code != http.StatusOK {
+		t.Fatalf("/team/setLevelPolicy: %s", w.Body)
+	}
+	return team, author, seniorIDs
+}
+
+type reassignResp struct {
+	PR         models.PullRequest `json:"pr"`
+	ReplacedBy string             `json:"replaced_by"`
+	LevelUnmet bool               `json:"level_unmet"`
+}
+
+func reassign(t *testing.T, h http.Handler, prID, oldUserID string) reassignResp {
+	t.Helper()
+	w := doJSON(t, h, http.MethodPost, "/pullRequest/reassign", reassignReq{PullRequestID: prID, OldUserID: oldUserID})
+	if w.Code != http.StatusOK {
+		t.Fatalf("/pullRequest/reassign %s: %s", oldUserID, w.Body)
+	}
+	var resp reassignResp
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestSeniorReplacedBySenior(t *testing.T) {
+	svc, cleanup := setupTestService(t)
+	defer cleanup()
+	h := NewHandler(svc)
+
+	_, author, seniors := setupLevelTeam(t, h, 2, 4, 1, 1)
+	prID := uniqueID("pr")
+	created := createPR(t, h, createPRReq{PullRequestID: prID, PullRequestName: "Levels", AuthorID: author})
+	if len(created.PR.AssignedReviewers) != 1 || !slices.Contains(seniors, created.PR.AssignedReviewers[0]) {
+		t.Fatalf("expected a senior reviewer, got %v", created.PR.AssignedReviewers)
+	}
+	if created.Staffing.LevelUnmet {
+		t.Fatalf("level reported unmet: %+v", created.Staffing)
+	}
+
+	old := created.PR.AssignedReviewers[0]
+	resp := reassign(t, h, prID, old)
+	if resp.ReplacedBy == old || !slices.Contains(seniors, resp.ReplacedBy) {
+		t.Fatalf("senior %s replaced by %s, want the other senior of %v", old, resp.ReplacedBy, seniors)
+	}
+	if resp.LevelUnmet {
+		t.Fatal("level reported unmet after a senior replacement")
+	}
+}
+
+func TestLevelUnmet(t *testing.T) {
+	svc, cleanup := setupTestService(t)
+	defer cleanup()
+	h := NewHandler(svc)
+
+	_, author, seniors := setupLevelTeam(t, h, 1, 2, 2, 2)
+	prID := uniqueID("pr")
+	created := createPR(t, h, createPRReq{PullRequestID: prID, PullRequestName: "Levels", AuthorID: author})
+	if !slices.Contains(created.PR.AssignedReviewers, seniors[0]) {
+		t.Fatalf("expected the only senior among %v", created.PR.AssignedReviewers)
+	}
+	if !created.Staffing.LevelUnmet {
+		t.Fatalf("one senior of two required not reported: %+v", created.Staffing)
+	}
+
+	resp := reassign(t, h, prID, seniors[0])
+	if !resp.LevelUnmet {
+		t.Fatal("replacing the only senior not reported as level_unmet")
+	}
+	if resp.ReplacedBy == "" || slices.Contains(seniors, resp.ReplacedBy) {
+		t.Fatalf("expected a junior replacement, got %q", resp.ReplacedBy)
+	}
+}
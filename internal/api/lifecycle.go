@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+	"github.com/Guardian1221/prsvc/internal/repo"
+	"github.com/Guardian1221/prsvc/internal/service"
+)
+
+func (h *Handler) handlePRReady(w http.ResponseWriter, r *http.Request) {
+	h.handlePRTransition(w, r, "MarkReady", h.svc.MarkReady)
+}
+
+func (h *Handler) handlePRReopen(w http.ResponseWriter, r *http.Request) {
+	h.handlePRTransition(w, r, "ReopenPullRequest", h.svc.ReopenPullRequest)
+}
+
+func (h *Handler) handlePRClose(w http.ResponseWriter, r *http.Request) {
+	h.handlePRTransition(w, r, "ClosePullRequest", func(ctx context.Context, prID string) (*models.PullRequest, *models.Staffing, error) {
+		pr, err := h.svc.ClosePullRequest(ctx, prID)
+		return pr, nil, err
+	})
+}
+
+func (h *Handler) handlePRTransition(w http.ResponseWriter, r *http.Request, op string, fn func(context.Context, string) (*models.PullRequest, *models.Staffing, error)) {
+	ctx, cancel := h.withTimeoutContext(r)
+	defer cancel()
+
+	var req prActionReq
+	if err := decodeBody(r, &req); err != nil {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "invalid json")
+		return
+	}
+	req.PullRequestID = pathParam(r, "id", req.PullRequestID)
+	if req.PullRequestID == "" {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "missing fields")
+		return
+	}
+
+	pr, staffing, err := fn(ctx, req.PullRequestID)
+	if err != nil {
+		switch err {
+		case repo.ErrInvalidTransition:
+			writeErrorJSON(w, http.StatusConflict, "INVALID_TRANSITION", "PR status does not allow this transition")
+		case service.ErrInvalidRequestedReviewer:
+			writeErrorJSON(w, http.StatusConflict, "INVALID_REVIEWER", "a requested reviewer is no longer an active member of the author's team")
+		case sql.ErrNoRows:
+			writeErrorJSON(w, http.StatusNotFound, "NOT_FOUND", "pr not found")
+		default:
+			slog.ErrorContext(ctx, op+" failed", "err", err)
+			writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
+		}
+		return
+	}
+
+	resp := map[string]any{"pr": pr}
+	if staffing != nil {
+		resp["staffing"] = staffing
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
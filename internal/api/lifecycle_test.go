@@ -0,0 +1,109 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+)
+
+func transitionPR(t *testing.T, h http.Handler, action, prID string) *httptest.ResponseRecorder {
+	t.Helper()
+	return doJSON(t, h, http.MethodPost, "/pullRequest/"+action, prActionReq{PullRequestID: prID})
+}
+
+func TestCloseReleasesReviewers(t *testing.T) {
+	svc, cleanup := setupTestService(t)
+	defer cleanup()
+	h := NewHandler(svc)
+
+	author, reviewer := setupCappedTeam(t, h)
+	prID := uniqueID("pr")
+	createPR(t, h, createPRReq{PullRequestID: prID, PullRequestName: "Close", AuthorID: author})
+
+	closed := decodePR(t, transitionPR(t, h, "close", prID))
+	if closed.Status != models.StatusClosed || closed.ClosedAt == nil || len(closed.AssignedReviewers) != 0 {
+		t.Fatalf("close: %+v", closed)
+	}
+	next := createPR(t, h, createPRReq{PullRequestID: uniqueID("pr"), PullRequestName: "Next", AuthorID: author})
+	if !slices.Equal(next.PR.AssignedReviewers, []string{reviewer}) || next.Staffing.CapacityLimited {
+		t.Fatalf("closing did not free the reviewer's capacity: %+v %+v", next.PR.AssignedReviewers, next.Staffing)
+	}
+}
+
+func TestReopenRestaffsWithExclusions(t *testing.T) {
+	svc, cleanup := setupTestService(t)
+	defer cleanup()
+	h := NewHandler(svc)
+
+	_, author, r1, r2, _ := setupExclusionTeam(t, h, 2)
+	prID := uniqueID("pr")
+	createPR(t, h, createPRReq{PullRequestID: prID, PullRequestName: "Reopen", AuthorID: author, ExcludedReviewers: []string{r2}})
+	decodePR(t, transitionPR(t, h, "close", prID))
+
+	reopened := decodePR(t, transitionPR(t, h, "reopen", prID))
+	if reopened.Status != models.StatusOpen || reopened.ClosedAt != nil {
+		t.Fatalf("reopen: %+v", reopened)
+	}
+	if !slices.Equal(reopened.AssignedReviewers, []string{r1}) {
+		t.Fatalf("reopen assigned %v, want only %s", reopened.AssignedReviewers, r1)
+	}
+}
+
+func TestReadyStaffsDraft(t *testing.T) {
+	svc, cleanup := setupTestService(t)
+	defer cleanup()
+	h := NewHandler(svc)
+
+	_, author, r1, r2, _ := setupExclusionTeam(t, h, 2)
+	prID := uniqueID("draft")
+	draft := createPR(t, h, createPRReq{PullRequestID: prID, PullRequestName: "Draft", AuthorID: author, Draft: true})
+	if draft.PR.Status != models.StatusDraft || len(draft.PR.AssignedReviewers) != 0 {
+		t.Fatalf("draft created with reviewers: %+v", draft.PR)
+	}
+
+	ready := decodePR(t, transitionPR(t, h, "ready", prID))
+	want := []string{r1, r2}
+	slices.Sort(want)
+	if ready.Status != models.StatusOpen || !slices.Equal(ready.AssignedReviewers, want) {
+		t.Fatalf("ready: %+v, want reviewers %v", ready, want)
+	}
+}
+
+func TestInvalidTransitions(t *testing.T) {
+	svc, cleanup := setupTestService(t)
+	defer cleanup()
+	h := NewHandler(svc)
+
+	_, author, _, _, _ := setupExclusionTeam(t, h, 1)
+	open := uniqueID("pr")
+	createPR(t, h, createPRReq{PullRequestID: open, PullRequestName: "Open", AuthorID: author})
+	closed := uniqueID("pr")
+	createPR(t, h, createPRReq{PullRequestID: closed, PullRequestName: "Closed", AuthorID: author})
+	decodePR(t, transitionPR(t, h, "close", closed))
+	merged := uniqueID("pr")
+	createPR(t, h, createPRReq{PullRequestID: merged, PullRequestName: "Merged", AuthorID: author})
+	decodePR(t, transitionPR(t, h, "merge", merged))
+
+	cases := []struct{ action, prID string }{
+		{"ready", open},
+		{"reopen", open},
+		{"ready", closed},
+		{"close", closed},
+		{"close", merged},
+		{"reopen", merged},
+		{"ready", merged},
+	}
+	for _, tc := range cases {
+		w := transitionPR(t, h, tc.action, tc.prID)
+		if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "INVALID_TRANSITION") {
+			t.Errorf("%s on %s: %d %s", tc.action, tc.prID, w.Code, w.Body)
+		}
+	}
+	if w := transitionPR(t, h, "close", uniqueID("missing")); w.Code != http.StatusNotFound {
+		t.Errorf("close on a missing PR: %d %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Guardian1221/prsvc/internal/logging"
+)
+
+func TestRequestLogPropagatesRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	var seen string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = logging.RequestID(r.Context())
+		writeErrorJSON(w, http.StatusNotFound, "NOT_FOUND", "pr not found")
+	})
+	h := RequestLog(next, logging.New(&buf, slog.LevelInfo))
+
+	req := httptest.NewRequest(http.MethodGet, "/team/get", nil)
+	req.Header.Set(RequestIDHeader, "client-id-1")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if seen != "client-id-1" || w.Header().Get(RequestIDHeader) != "client-id-1" {
+		t.Fatalf("handler saw %q, response header %q", seen, w.Header().Get(RequestIDHeader))
+	}
+	var body ErrorResponse
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil || body.RequestID != "client-id-1" {
+		t.Fatalf("body %+v, err %v", body, err)
+	}
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("log line %q: %v", buf.String(), err)
+	}
+	if line["request_id"] != "client-id-1" || line["route"] != "/team/get" || line["status"] != float64(404) {
+		t.Fatalf("log line %v", line)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/team/get", nil)
+	req.Header.Set(RequestIDHeader, "bad id\n")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if id := w.Header().Get(RequestIDHeader); id == "" || id == "bad id\n" {
+		t.Fatalf("unusable client id was not replaced: %q", id)
+	}
+}
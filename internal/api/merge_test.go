@@ -0,0 +1,51 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+)
+
+type fakeRecorder struct {
+	merged map[bool]int
+}
+
+func (f *fakeRecorder) PRCreated()         {}
+func (f *fakeRecorder) Reassigned(string)  {}
+func (f *fakeRecorder) NoCandidate(string) {}
+func (f *fakeRecorder) Merged(external bool) {
+	f.merged[external]++
+}
+
+func TestRepeatedMergeCountedOnce(t *testing.T) {
+	svc, cleanup := setupTestService(t)
+	defer cleanup()
+	rec := &fakeRecorder{merged: map[bool]int{}}
+	svc.SetRecorder(rec)
+	h := NewHandler(svc)
+
+	team, author := uniqueID("team"), uniqueID("author")
+	w := doJSON(t, h, http.MethodPost, "/team/add", models.Team{TeamName: team, Members: []models.TeamMember{
+		{UserID: author, Username: "Author", IsActive: true},
+	}})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("/team/add: %s", w.Body)
+	}
+	prID := uniqueID("pr")
+	createPR(t, h, createPRReq{PullRequestID: prID, PullRequestName: "Merge", AuthorID: author})
+
+	for i := 0; i < 2; i++ {
+		w = doJSON(t, h, http.MethodPost, "/pullRequest/merge", prActionReq{PullRequestID: prID})
+		if w.Code != http.StatusOK {
+			t.Fatalf("merge %d: %s", i, w.Body)
+		}
+	}
+	if _, err := svc.RecordExternalMerge(context.Background(), prID); err != nil {
+		t.Fatal(err)
+	}
+	if rec.merged[false] != 1 || rec.merged[true] != 0 {
+		t.Fatalf("merges counted %v, want one api merge", rec.merged)
+	}
+}
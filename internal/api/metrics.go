@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// RequestObserver records one finished HTTP request.
+type RequestObserver interface {
+	ObserveRequest(route, method string, status int, elapsed time.Duration)
+}
+
+// Instrument reports every request to o, labelled with its route template,
+// or with "unmatched" for requests no route serves, so neither IDs in paths
+// nor scanners can blow up the label set.
+func Instrument(next http.Handler, o RequestObserver) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		r, info := withRequestInfo(r)
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		o.ObserveRequest(info.route(r), r.Method, rec.status, time.Since(start))
+	})
+}
+
+type requestInfoKey struct{}
+
+// requestInfo lets inner handlers tell the outer middleware what they
+// learnt about a request.
+type requestInfo struct {
+	unmatched bool
+	user      string
+}
+
+// withRequestInfo returns r carrying a requestInfo, reusing one an outer
+// middleware already attached.
+func withRequestInfo(r *http.Request) (*http.Request, *requestInfo) {
+	if info, ok := r.Context().Value(requestInfoKey{}).(*requestInfo); ok {
+		return r, info
+	}
+	info := &requestInfo{}
+	return r.WithContext(context.WithValue(r.Context(), requestInfoKey{}, info)), info
+}
+
+func (info *requestInfo) route(r *http.Request) string {
+	if info.unmatched {
+		return "unmatched"
+	}
+	return routeName(r)
+}
+
+// markUnmatched tells the middleware that no route served r.
+func markUnmatched(r *http.Request) {
+	if info, ok := r.Context().Value(requestInfoKey{}).(*requestInfo); ok {
+		info.unmatched = true
+	}
+}
+
+// markUser tells the middleware who made r.
+func markUser(r *http.Request, user string) {
+	if info, ok := r.Context().Value(requestInfoKey{}).(*requestInfo); ok {
+		info.user = user
+	}
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	if !s.wroteHeader {
+		s.status = code
+		s.wroteHeader = true
+	}
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	s.wroteHeader = true
+	return s.ResponseWriter.Write(b)
+}
+
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
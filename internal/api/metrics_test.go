@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type observed struct {
+	route, method string
+	status        int
+}
+
+type fakeObserver struct {
+	seen []observed
+}
+
+func (f *fakeObserver) ObserveRequest(route, method string, status int, _ time.Duration) {
+	f.seen = append(f.seen, observed{route, method, status})
+}
+
+func TestInstrumentLabelsRoutes(t *testing.T) {
+	o := &fakeObserver{}
+	h := Instrument(NewHandler(nil), o)
+
+	for _, path := range []string{"/health", "/wp-login.php"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	want := []observed{
+		{"/health", http.MethodGet, http.StatusOK},
+		{"unmatched", http.MethodGet, http.StatusNotFound},
+	}
+	if len(o.seen) != len(want) {
+		t.Fatalf("got %+v", o.seen)
+	}
+	for i := range want {
+		if o.seen[i] != want[i] {
+			t.Errorf("request %d: got %+v, want %+v", i, o.seen[i], want[i])
+		}
+	}
+}
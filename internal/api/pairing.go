@@ -0,0 +1,83 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/Guardian1221/prsvc/internal/service"
+)
+
+type pairingWindowReq struct {
+	TeamName          string `json:"team_name"`
+	PairingWindowDays int    `json:"pairing_window_days"`
+}
+
+func (h *Handler) handleTeamSetPairingWindow(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := h.withTimeoutContext(r)
+	defer cancel()
+
+	var req pairingWindowReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "invalid json")
+		return
+	}
+	if req.TeamName == "" {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "team_name required")
+		return
+	}
+
+	t, err := h.svc.SetTeamPairingWindow(ctx, req.TeamName, req.PairingWindowDays)
+	if err != nil {
+		if err == service.ErrInvalidPairingWindow {
+			writeErrorJSON(w, http.StatusBadRequest, "INVALID_POLICY", "pairing_window_days must be between 0 and 365")
+			return
+		}
+		if err == sql.ErrNoRows {
+			writeErrorJSON(w, http.StatusNotFound, "NOT_FOUND", "team not found")
+			return
+		}
+		slog.ErrorContext(ctx, "SetTeamPairingWindow failed", "err", err)
+		writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"team": t})
+}
+
+func (h *Handler) handleStats(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := h.withTimeoutContext(r)
+	defer cancel()
+
+	teamName := r.URL.Query().Get("team_name")
+	windowDays := 0
+	if v := r.URL.Query().Get("window_days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "window_days must be a number")
+			return
+		}
+		windowDays = n
+	}
+
+	stats, err := h.svc.PairingStats(ctx, teamName, windowDays)
+	if err != nil {
+		if err == service.ErrInvalidPairingWindow {
+			writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "window_days must be between 1 and 365")
+			return
+		}
+		if err == sql.ErrNoRows {
+			writeErrorJSON(w, http.StatusNotFound, "NOT_FOUND", "team not found")
+			return
+		}
+		slog.ErrorContext(ctx, "PairingStats failed", "err", err)
+		writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"pairing": stats})
+}
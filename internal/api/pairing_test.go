@@ -0,0 +1,53 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+)
+
+func TestPairingStatsKeepReleasedReviewers(t *testing.T) {
+	svc, cleanup := setupTestService(t)
+	defer cleanup()
+	h := NewHandler(svc)
+
+	team, author := uniqueID("team"), uniqueID("author")
+	w := doJSON(t, h, http.MethodPost, "/team/add", models.Team{TeamName: team, MinReviewers: 1, MaxReviewers: 1, Members: []models.TeamMember{
+		{UserID: author, Username: "Author", IsActive: true},
+		{UserID: uniqueID("r1"), Username: "R1", IsActive: true},
+		{UserID: uniqueID("r2"), Username: "R2", IsActive: true},
+	}})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("/team/add: %s", w.Body)
+	}
+
+	prID := uniqueID("pr")
+	created := createPR(t, h, createPRReq{PullRequestID: prID, PullRequestName: "Pairing", AuthorID: author})
+	if len(created.PR.AssignedReviewers) != 1 {
+		t.Fatalf("expected one reviewer, got %v", created.PR.AssignedReviewers)
+	}
+	w = doJSON(t, h, http.MethodPost, "/pullRequest/reassign", reassignReq{PullRequestID: prID, OldUserID: created.PR.AssignedReviewers[0]})
+	if w.Code != http.StatusOK {
+		t.Fatalf("/pullRequest/reassign: %s", w.Body)
+	}
+	w = doJSON(t, h, http.MethodPost, "/pullRequest/close", prActionReq{PullRequestID: prID})
+	if w.Code != http.StatusOK {
+		t.Fatalf("/pullRequest/close: %s", w.Body)
+	}
+
+	w = doJSON(t, h, http.MethodGet, "/stats?team_name="+team, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("/stats: %s", w.Body)
+	}
+	var resp struct {
+		Pairing models.PairingStats `json:"pairing"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Pairing.Assignments != 2 || resp.Pairing.DistinctPairs != 2 {
+		t.Fatalf("expected both reviewers counted, got %+v", resp.Pairing)
+	}
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Guardian1221/prsvc/internal/auth"
+	"github.com/Guardian1221/prsvc/internal/ratelimit"
+)
+
+// RateLimit wraps next with per-client token buckets, one per route
+// template such as /pullRequests/{id}/merge. It runs after RequireAuth:
+// clients are told apart by the authenticated subject, or by IP address on
+// public routes. Every limited response carries the RateLimit-* headers;
+// rejected ones get 429 with RATE_LIMITED and Retry-After.
+func RateLimit(next http.Handler, l *ratelimit.Limiter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/", "/health", "/livez", "/readyz":
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		res := l.Allow(clientKey(r), routeName(r), time.Now())
+		if !writeRateLimit(w, res) {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// LimitAuthFailures wraps RequireAuth so that every 401 counts against the
+// client IP's bucket, and an IP out of tokens is turned away before its
+// token is looked up. Made-up tokens thus cannot buy fresh buckets or
+// unlimited authentication lookups.
+func LimitAuthFailures(next http.Handler, l *ratelimit.Limiter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := "unauthorized:" + ipKey(r)
+		if res := l.Peek(key, "", time.Now()); !res.Allowed {
+			writeRateLimit(w, res)
+			return
+		}
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+		if rec.status == http.StatusUnauthorized {
+			l.Allow(key, "", time.Now())
+		}
+	})
+}
+
+// writeRateLimit sets the RateLimit-* headers for res and, when res was not
+// allowed, writes the 429 response. It reports whether to go on.
+func writeRateLimit(w http.ResponseWriter, res ratelimit.Result) bool {
+	h := w.Header()
+	h.Set("RateLimit-Policy", strconv.Itoa(res.Limit.Requests)+";w="+strconv.Itoa(int(res.Limit.Per/time.Second))+";burst="+strconv.Itoa(res.Limit.Burst))
+	h.Set("RateLimit-Limit", strconv.Itoa(res.Limit.Burst))
+	h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+	if !res.Allowed {
+		h.Set("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+		writeErrorJSON(w, http.StatusTooManyRequests, "RATE_LIMITED", "too many requests, retry later")
+		return false
+	}
+	return true
+}
+
+func clientKey(r *http.Request) string {
+	if p := auth.FromContext(r.Context()); p != nil {
+		return "subject:" + p.Subject
+	}
+	return ipKey(r)
+}
+
+func ipKey(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
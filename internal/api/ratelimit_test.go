@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Guardian1221/prsvc/internal/auth"
+	"github.com/Guardian1221/prsvc/internal/ratelimit"
+)
+
+func TestRateLimit(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	h := RateLimit(next, ratelimit.New(ratelimit.Limit{Requests: 100, Per: time.Second, Burst: 100}, map[string]ratelimit.Limit{
+		"/pullRequest/create": {Requests: 1, Per: time.Minute, Burst: 1},
+	}))
+
+	send := func(subject, addr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/pullRequest/create", nil)
+		req.RemoteAddr = addr
+		if subject != "" {
+			req = req.WithContext(auth.NewContext(req.Context(), &auth.Principal{Subject: subject, Scope: auth.ScopeUser}))
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("bot", "10.0.0.1:1234")
+	if w.Code != http.StatusOK || w.Header().Get("RateLimit-Limit") != "1" || w.Header().Get("RateLimit-Remaining") != "0" {
+		t.Fatalf("first request: %d %v", w.Code, w.Header())
+	}
+	w = send("bot", "10.0.0.2:1234")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "60" {
+		t.Fatalf("second request: %d %v", w.Code, w.Header())
+	}
+	var body ErrorResponse
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil || body.Error.Code != "RATE_LIMITED" {
+		t.Fatalf("body %+v, err %v", body, err)
+	}
+
+	if w := send("", "10.0.0.1:1234"); w.Code != http.StatusOK {
+		t.Fatalf("anonymous client limited by the subject's bucket: %d", w.Code)
+	}
+	if w := send("", "10.0.0.1:5678"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("same IP on another port not limited: %d", w.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/pullRequests", nil)
+	req = req.WithContext(auth.NewContext(req.Context(), &auth.Principal{Subject: "bot", Scope: auth.ScopeUser}))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("resource alias got its own bucket: %d", w.Code)
+	}
+}
+
+func TestLimitAuthFailures(t *testing.T) {
+	lookups := 0
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups++
+		writeErrorJSON(w, http.StatusUnauthorized, "UNAUTHORIZED", "invalid or revoked token")
+	})
+	h := LimitAuthFailures(next, ratelimit.New(ratelimit.Limit{Requests: 2, Per: time.Minute, Burst: 2}, nil))
+
+	send := func(token, addr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/team/get", nil)
+		req.RemoteAddr = addr
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	for i, token := range []string{"guess-1", "guess-2"} {
+		if w := send(token, "10.0.0.1:1234"); w.Code != http.StatusUnauthorized {
+			t.Fatalf("guess %d: %d", i, w.Code)
+		}
+	}
+	if w := send("guess-3", "10.0.0.1:1234"); w.Code != http.StatusTooManyRequests || lookups != 2 {
+		t.Fatalf("fresh token after two failures: %d, %d lookups", w.Code, lookups)
+	}
+	if w := send("guess-4", "10.0.0.2:1234"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("other IP limited: %d", w.Code)
+	}
+}
@@ -0,0 +1,230 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/Guardian1221/prsvc/internal/auth"
+	"github.com/Guardian1221/prsvc/internal/models"
+)
+
+// scopePublic marks routes served without a token.
+const scopePublic auth.Scope = ""
+
+// route is one endpoint. pattern is an http.ServeMux pattern; a GET route
+// also answers HEAD. name labels metrics, spans and rate limit buckets
+// without one series per team or PR; a resource route shares the name of
+// the RPC route it mirrors, so both count against one limit.
+type route struct {
+	pattern string
+	name    string
+	scope   auth.Scope
+	handle  func(h *Handler, w http.ResponseWriter, r *http.Request)
+}
+
+// routes lists every endpoint. The RPC-style routes are kept for existing
+// clients; the resource routes take the team, PR or user from the path.
+// Reads and PR actions need the user scope; managing teams, users, code
+// owners, VCS mappings and tokens needs admin. The webhook checks its own
+// secret.
+var routes = []route{
+	{"GET /{$}", "/", scopePublic, (*Handler).handleRoot},
+	{"GET /health", "/health", scopePublic, (*Handler).handleHealth},
+	{"GET /livez", "/livez", scopePublic, (*Handler).handleLivez},
+	{"GET /readyz", "/readyz", scopePublic, (*Handler).handleReadyz},
+	{"POST /webhooks/gitlab", "/webhooks/gitlab", scopePublic, (*Handler).handleGitLabWebhook},
+
+	{"POST /team/add", "/team/add", auth.ScopeAdmin, (*Handler).handleTeamAdd},
+	{"GET /team/get", "/team/get", auth.ScopeUser, (*Handler).handleTeamGet},
+	{"POST /team/setReviewerPolicy", "/team/setReviewerPolicy", auth.ScopeAdmin, (*Handler).handleTeamSetReviewerPolicy},
+	{"POST /team/setFallbacks", "/team/setFallbacks", auth.ScopeAdmin, (*Handler).handleTeamSetFallbacks},
+	{"POST /team/setRequiredApprovals", "/team/setRequiredApprovals", auth.ScopeAdmin, (*Handler).handleTeamSetRequiredApprovals},
+	{"POST /team/setReviewSLA", "/team/setReviewSLA", auth.ScopeAdmin, (*Handler).handleTeamSetReviewSLA},
+	{"POST /team/setEscalationPolicy", "/team/setEscalationPolicy", auth.ScopeAdmin, (*Handler).handleTeamSetEscalationPolicy},
+	{"POST /team/setPairingWindow", "/team/setPairingWindow", auth.ScopeAdmin, (*Handler).handleTeamSetPairingWindow},
+	{"POST /team/addExclusion", "/team/addExclusion", auth.ScopeAdmin, (*Handler).handleTeamAddExclusion},
+	{"POST /team/deleteExclusion", "/team/deleteExclusion", auth.ScopeAdmin, (*Handler).handleTeamDeleteExclusion},
+	{"GET /team/getExclusions", "/team/getExclusions", auth.ScopeUser, (*Handler).handleTeamGetExclusions},
+	{"POST /team/setLevelPolicy", "/team/setLevelPolicy", auth.ScopeAdmin, (*Handler).handleTeamSetLevelPolicy},
+
+	{"POST /users/setMaxOpenReviews", "/users/setMaxOpenReviews", auth.ScopeAdmin, (*Handler).handleUserSetMaxOpenReviews},
+	{"POST /users/setWorkingHours", "/users/setWorkingHours", auth.ScopeAdmin, (*Handler).handleUserSetWorkingHours},
+	{"POST /users/setLevel", "/users/setLevel", auth.ScopeAdmin, (*Handler).handleUserSetLevel},
+	{"POST /users/addAbsence", "/users/addAbsence", auth.ScopeAdmin, (*Handler).handleAbsenceAdd},
+	{"GET /users/getAbsences", "/users/getAbsences", auth.ScopeUser, (*Handler).handleAbsenceList},
+	{"POST /users/deleteAbsence", "/users/deleteAbsence", auth.ScopeAdmin, (*Handler).handleAbsenceDelete},
+
+	{"POST /pullRequest/create", "/pullRequest/create", auth.ScopeUser, (*Handler).handlePRCreate},
+	{"POST /pullRequest/reassign", "/pullRequest/reassign", auth.ScopeUser, (*Handler).handlePRReassign},
+	{"GET /pullRequest/overdue", "/pullRequest/overdue", auth.ScopeUser, (*Handler).handlePROverdue},
+	{"GET /pullRequest/reassignments", "/pullRequest/reassignments", auth.ScopeUser, (*Handler).handlePRReassignments},
+	{"POST /pullRequest/approve", "/pullRequest/approve", auth.ScopeUser, (*Handler).handlePRApprove},
+	{"POST /pullRequest/requestChanges", "/pullRequest/requestChanges", auth.ScopeUser, (*Handler).handlePRRequestChanges},
+	{"POST /pullRequest/merge", "/pullRequest/merge", auth.ScopeUser, (*Handler).handlePRMerge},
+	{"POST /pullRequest/ready", "/pullRequest/ready", auth.ScopeUser, (*Handler).handlePRReady},
+	{"POST /pullRequest/close", "/pullRequest/close", auth.ScopeUser, (*Handler).handlePRClose},
+	{"POST /pullRequest/reopen", "/pullRequest/reopen", auth.ScopeUser, (*Handler).handlePRReopen},
+
+	{"POST /codeowners/set", "/codeowners/set", auth.ScopeAdmin, (*Handler).handleCodeOwnersSet},
+	{"GET /codeowners/get", "/codeowners/get", auth.ScopeUser, (*Handler).handleCodeOwnersGet},
+	{"POST /vcs/mapUser", "/vcs/mapUser", auth.ScopeAdmin, (*Handler).handleVCSMapUser},
+	{"GET /stats", "/stats", auth.ScopeUser, (*Handler).handleStats},
+
+	{"POST /admin/tokens/issue", "/admin/tokens/issue", auth.ScopeAdmin, (*Handler).handleTokenIssue},
+	{"POST /admin/tokens/revoke", "/admin/tokens/revoke", auth.ScopeAdmin, (*Handler).handleTokenRevoke},
+	{"GET /admin/tokens/list", "/admin/tokens/list", auth.ScopeAdmin, (*Handler).handleTokenList},
+
+	{"POST /teams", "/team/add", auth.ScopeAdmin, (*Handler).handleTeamAdd},
+	{"GET /teams/{team}", "/team/get", auth.ScopeUser, (*Handler).handleTeamGet},
+	{"GET /teams/{team}/exclusions", "/team/getExclusions", auth.ScopeUser, (*Handler).handleTeamGetExclusions},
+	{"GET /teams/{team}/overdue", "/pullRequest/overdue", auth.ScopeUser, (*Handler).handlePROverdue},
+
+	{"POST /pullRequests", "/pullRequest/create", auth.ScopeUser, (*Handler).handlePRCreate},
+	{"GET /pullRequests/{id}/reassignments", "/pullRequest/reassignments", auth.ScopeUser, (*Handler).handlePRReassignments},
+	{"POST /pullRequests/{id}/reassign", "/pullRequest/reassign", auth.ScopeUser, (*Handler).handlePRReassign},
+	{"POST /pullRequests/{id}/approve", "/pullRequest/approve", auth.ScopeUser, (*Handler).handlePRApprove},
+	{"POST /pullRequests/{id}/requestChanges", "/pullRequest/requestChanges", auth.ScopeUser, (*Handler).handlePRRequestChanges},
+	{"POST /pullRequests/{id}/merge", "/pullRequest/merge", auth.ScopeUser, (*Handler).handlePRMerge},
+	{"POST /pullRequests/{id}/ready", "/pullRequest/ready", auth.ScopeUser, (*Handler).handlePRReady},
+	{"POST /pullRequests/{id}/close", "/pullRequest/close", auth.ScopeUser, (*Handler).handlePRClose},
+	{"POST /pullRequests/{id}/reopen", "/pullRequest/reopen", auth.ScopeUser, (*Handler).handlePRReopen},
+
+	{"GET /users/{user}/absences", "/users/getAbsences", auth.ScopeUser, (*Handler).handleAbsenceList},
+	{"POST /users/{user}/absences", "/users/addAbsence", auth.ScopeAdmin, (*Handler).handleAbsenceAdd},
+	{"DELETE /absences/{id}", "/users/deleteAbsence", auth.ScopeAdmin, (*Handler).handleAbsenceDelete},
+}
+
+// routeIndex resolves requests to routes for the middleware, which runs
+// before the Handler and has no access to its mux.
+var routeIndex, routesByPattern = indexRoutes()
+
+func indexRoutes() (*http.ServeMux, map[string]*route) {
+	mux := http.NewServeMux()
+	byPattern := make(map[string]*route, len(routes))
+	for i := range routes {
+		mux.Handle(routes[i].pattern, http.NotFoundHandler())
+		byPattern[routes[i].pattern] = &routes[i]
+	}
+	return mux, byPattern
+}
+
+// lookupRoute returns the route serving r, or nil when the path is unknown
+// or does not accept r.Method.
+func lookupRoute(r *http.Request) *route {
+	_, pattern := routeIndex.Handler(r)
+	return routesByPattern[pattern]
+}
+
+// routeName labels r by its route, or "unmatched".
+func routeName(r *http.Request) string {
+	if rt := lookupRoute(r); rt != nil {
+		return rt.name
+	}
+	return "unmatched"
+}
+
+// allowedMethods lists the methods the path of r accepts, for the Allow
+// header of 405 and OPTIONS responses.
+func allowedMethods(r *http.Request) []string {
+	var allowed []string
+	for _, m := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		probe := r.Clone(r.Context())
+		probe.Method = m
+		if lookupRoute(probe) != nil {
+			allowed = append(allowed, m)
+			if m == http.MethodGet {
+				allowed = append(allowed, http.MethodHead)
+			}
+		}
+	}
+	if len(allowed) > 0 {
+		allowed = append(allowed, http.MethodOptions)
+	}
+	sort.Strings(allowed)
+	return allowed
+}
+
+func (h *Handler) newMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	for i := range routes {
+		rt := &routes[i]
+		mux.HandleFunc(rt.pattern, func(w http.ResponseWriter, r *http.Request) {
+			rt.handle(h, w, r)
+		})
+	}
+	return mux
+}
+
+// ServeHTTP dispatches to the route matching r. A known path with the wrong
+// method gets 405 and an Allow header; OPTIONS answers with the Allow header
+// alone.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, pattern := h.mux.Handler(r); pattern != "" {
+		h.mux.ServeHTTP(w, r)
+		return
+	}
+
+	allowed := allowedMethods(r)
+	if len(allowed) == 0 {
+		markUnmatched(r)
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	markUnmatched(r)
+	writeErrorJSON(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", r.Method+" not allowed")
+}
+
+// Middleware wraps a handler with cross-cutting behaviour.
+type Middleware func(http.Handler) http.Handler
+
+// Chain wraps h in mws; the first middleware sees the request first.
+func Chain(h http.Handler, mws ...Middleware) http.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// pathParam returns the {name} wildcard of a resource route, or fallback
+// on the RPC route, which carries the value in the body or query.
+func pathParam(r *http.Request, name, fallback string) string {
+	if v := r.PathValue(name); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// decodeBody decodes a JSON body into v; an empty body leaves v unchanged,
+// so resource routes whose path says everything can be called without one.
+func decodeBody(r *http.Request, v any) error {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+func (h *Handler) handleRoot(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(`{"status":"ok"}`))
+}
+
+func (h *Handler) handlePRApprove(w http.ResponseWriter, r *http.Request) {
+	h.handlePRVerdict(w, r, models.VerdictApproved)
+}
+
+func (h *Handler) handlePRRequestChanges(w http.ResponseWriter, r *http.Request) {
+	h.handlePRVerdict(w, r, models.VerdictChangesRequested)
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRouterMethods(t *testing.T) {
+	h := NewHandler(nil)
+
+	cases := []struct {
+		method, path string
+		want         int
+		allow        string
+	}{
+		{http.MethodGet, "/health", http.StatusOK, ""},
+		{http.MethodHead, "/livez", http.StatusOK, ""},
+		{http.MethodPost, "/health", http.StatusMethodNotAllowed, "GET, HEAD, OPTIONS"},
+		{http.MethodGet, "/team/add", http.StatusMethodNotAllowed, "OPTIONS, POST"},
+		{http.MethodPut, "/pullRequests/pr-1/merge", http.StatusMethodNotAllowed, "OPTIONS, POST"},
+		{http.MethodOptions, "/teams/backend", http.StatusNoContent, "GET, HEAD, OPTIONS"},
+		{http.MethodOptions, "/teams", http.StatusNoContent, "OPTIONS, POST"},
+		{http.MethodGet, "/teams/backend/members", http.StatusNotFound, ""},
+		{http.MethodOptions, "/wp-login.php", http.StatusNotFound, ""},
+		{http.MethodPost, "/webhooks/gitlab", http.StatusNotFound, ""},
+	}
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(c.method, c.path, nil))
+		if w.Code != c.want {
+			t.Errorf("%s %s: status %d, want %d", c.method, c.path, w.Code, c.want)
+		}
+		if got := w.Header().Get("Allow"); got != c.allow {
+			t.Errorf("%s %s: Allow %q, want %q", c.method, c.path, got, c.allow)
+		}
+		if c.want == http.StatusMethodNotAllowed && !strings.Contains(w.Body.String(), "METHOD_NOT_ALLOWED") {
+			t.Errorf("%s %s: body %s", c.method, c.path, w.Body)
+		}
+	}
+}
+
+func TestRouteName(t *testing.T) {
+	cases := []struct{ method, path, want string }{
+		{http.MethodGet, "/", "/"},
+		{http.MethodGet, "/team/get", "/team/get"},
+		{http.MethodGet, "/teams/backend", "/team/get"},
+		{http.MethodPost, "/pullRequests", "/pullRequest/create"},
+		{http.MethodPost, "/pullRequests/pr-1/merge", "/pullRequest/merge"},
+		{http.MethodDelete, "/absences/7", "/users/deleteAbsence"},
+		{http.MethodPost, "/pullRequests/pr-1/teleport", "unmatched"},
+	}
+	for _, c := range cases {
+		if got := routeName(httptest.NewRequest(c.method, c.path, nil)); got != c.want {
+			t.Errorf("%s %s: got %q, want %q", c.method, c.path, got, c.want)
+		}
+	}
+}
+
+func TestRouteNamesAreRPCPaths(t *testing.T) {
+	rpc := map[string]bool{}
+	for _, rt := range routes {
+		if _, path, _ := strings.Cut(rt.pattern, " "); path == rt.name {
+			rpc[rt.name] = true
+		}
+	}
+	for _, rt := range routes {
+		if rt.name != "/" && !rpc[rt.name] {
+			t.Errorf("%s is named %q, which no RPC route serves", rt.pattern, rt.name)
+		}
+	}
+}
+
+func TestPathParam(t *testing.T) {
+	var got []string
+	mux := http.NewServeMux()
+	read := func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, pathParam(r, "team", r.URL.Query().Get("team_name")))
+	}
+	mux.HandleFunc("GET /teams/{team}", read)
+	mux.HandleFunc("GET /team/get", read)
+
+	for _, path := range []string{"/teams/backend", "/team/get?team_name=frontend"} {
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	if len(got) != 2 || got[0] != "backend" || got[1] != "frontend" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestChainOrder(t *testing.T) {
+	var order []string
+	mw := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	h := Chain(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		order = append(order, "handler")
+	}), mw("outer"), mw("inner"))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if strings.Join(order, ",") != "outer,inner,handler" {
+		t.Fatalf("got %v", order)
+	}
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Guardian1221/prsvc/internal/service"
+)
+
+func (h *Handler) handlePROverdue(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := h.withTimeoutContext(r)
+	defer cancel()
+
+	teamName := pathParam(r, "team", r.URL.Query().Get("team_name"))
+	overdue, err := h.svc.ListOverdueReviews(ctx, teamName, time.Now())
+	if err != nil {
+		slog.ErrorContext(ctx, "ListOverdueReviews failed", "err", err)
+		writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"overdue": overdue})
+}
+
+type reviewSLAReq struct {
+	TeamName         string `json:"team_name"`
+	ReviewSLAMinutes int    `json:"review_sla_minutes"`
+}
+
+func (h *Handler) handleTeamSetReviewSLA(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := h.withTimeoutContext(r)
+	defer cancel()
+
+	var req reviewSLAReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "invalid json")
+		return
+	}
+	if req.TeamName == "" {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "team_name required")
+		return
+	}
+
+	t, err := h.svc.SetTeamReviewSLA(ctx, req.TeamName, req.ReviewSLAMinutes)
+	if err != nil {
+		if err == service.ErrInvalidSLA {
+			writeErrorJSON(w, http.StatusBadRequest, "INVALID_POLICY", "review_sla_minutes must be positive")
+			return
+		}
+		if err == sql.ErrNoRows {
+			writeErrorJSON(w, http.StatusNotFound, "NOT_FOUND", "team not found")
+			return
+		}
+		slog.ErrorContext(ctx, "SetTeamReviewSLA failed", "err", err)
+		writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"team": t})
+}
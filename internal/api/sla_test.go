@@ -0,0 +1,280 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+	"github.com/Guardian1221/prsvc/internal/service"
+)
+
+type fakeNotifier struct {
+	mu        sync.Mutex
+	reminders []models.OverdueReview
+	// failFor makes reminders about this PR fail.
+	failFor string
+}
+
+func (f *fakeNotifier) RemindOverdue(_ context.Context, r models.OverdueReview) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.PullRequestID == f.failFor {
+		return errors.New("notifier unavailable")
+	}
+	f.reminders = append(f.reminders, r)
+	return nil
+}
+
+func (f *fakeNotifier) setFailFor(prID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failFor = prID
+}
+
+// remindersFor filters out reminders about PRs of other tests sharing the
+// database.
+func (f *fakeNotifier) remindersFor(prID string) []models.OverdueReview {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var res []models.OverdueReview
+	for _, r := range f.reminders {
+		if r.PullRequestID == prID {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// setupSLATeam creates a team whose reviewers work around the clock, so
+// working time equals wall-clock time, with a one hour review SLA, and
+// opens a PR with one reviewer.
+func setupSLATeam(t *testing.T, svc *service.Service, h http.Handler) (team, prID, reviewer string) {
+	t.Helper()
+	ctx := context.Background()
+	team = uniqueID("team")
+	author, r1, r2 := uniqueID("author"), uniqueID("r1"), uniqueID("r2")
+	w := doJSON(t, h, http.MethodPost, "/team/add", models.Team{TeamName: team, MinReviewers: 1, MaxReviewers: 1, Members: []models.TeamMember{
+		{UserID: author, Username: "A", IsActive: true},
+		{UserID: r1, Username: "R1", IsActive: true},
+		{UserID: r2, Username: "R2", IsActive: true},
+	}})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("/team/add: %s", w.Body)
+	}
+	for _, id := range []string{r1, r2} {
+		if _, err := svc.SetUserWorkingHours(ctx, id, models.WorkingHours{TimeZone: "UTC", WorkStartMinute: 0, WorkEndMinute: 24 * 60, WorkDays: 1<<7 - 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := svc.SetTeamReviewSLA(ctx, team, 60); err != nil {
+		t.Fatal(err)
+	}
+
+	prID = uniqueID("pr")
+	created := createPR(t, h, createPRReq{PullRequestID: prID, PullRequestName: "SLA", AuthorID: author})
+	if len(created.PR.AssignedReviewers) != 1 {
+		t.Fatalf("expected one reviewer, got %v", created.PR.AssignedReviewers)
+	}
+	return team, prID, created.PR.AssignedReviewers[0]
+}
+
+func TestListOverdueReviews(t *testing.T) {
+	svc, cleanup := setupTestService(t)
+	defer cleanup()
+	h := NewHandler(svc)
+	ctx := context.Background()
+
+	team, prID, reviewer := setupSLATeam(t, svc, h)
+
+	overdue, err := svc.ListOverdueReviews(ctx, team, time.Now().Add(30*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(overdue) != 0 {
+		t.Fatalf("review within its SLA listed: %+v", overdue)
+	}
+
+	overdue, err = svc.ListOverdueReviews(ctx, team, time.Now().Add(2*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(overdue) != 1 || overdue[0].PullRequestID != prID || overdue[0].ReviewerID != reviewer {
+		t.Fatalf("expected the PR's review overdue, got %+v", overdue)
+	}
+	if m := overdue[0].OverdueMinutes; m < 58 || m > 61 {
+		t.Fatalf("overdue_minutes = %d, want about 60", m)
+	}
+}
+
+func TestSendOverdueRemindersOnce(t *testing.T) {
+	svc, cleanup := setupTestService(t)
+	defer cleanup()
+	h := NewHandler(svc)
+	ctx := context.Background()
+	notifier := &fakeNotifier{}
+	svc.SetReminderNotifier(notifier)
+
+	_, prID, reviewer := setupSLATeam(t, svc, h)
+
+	svc.SetClock(func() time.Time { return time.Now().Add(30 * time.Minute) })
+	if err := svc.SendOverdueReminders(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := notifier.remindersFor(prID); len(got) != 0 {
+		t.Fatalf("reminded before the SLA passed: %+v", got)
+	}
+
+	svc.SetClock(func() time.Time { return time.Now().Add(2 * time.Hour) })
+	for i := 0; i < 2; i++ {
+		if err := svc.SendOverdueReminders(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got := notifier.remindersFor(prID)
+	if len(got) != 1 || got[0].ReviewerID != reviewer {
+		t.Fatalf("expected exactly one reminder, got %+v", got)
+	}
+}
+
+func TestSendOverdueRemindersContinuesPastFailure(t *testing.T) {
+	svc, cleanup := setupTestService(t)
+	defer cleanup()
+	h := NewHandler(svc)
+	ctx := context.Background()
+	notifier := &fakeNotifier{}
+	svc.SetReminderNotifier(notifier)
+
+	_, failing, _ := setupSLATeam(t, svc, h)
+	_, ok, _ := setupSLATeam(t, svc, h)
+	notifier.setFailFor(failing)
+
+	svc.SetClock(func() time.Time { return time.Now().Add(2 * time.Hour) })
+	if err := svc.SendOverdueReminders(ctx); err == nil {
+		t.Fatal("failed reminder not reported")
+	}
+	if got := notifier.remindersFor(ok); len(got) != 1 {
+		t.Fatalf("reminder after the failing one not sent: %+v", got)
+	}
+
+	// The failed reminder was not marked sent, so the next run retries it.
+	notifier.setFailFor("")
+	if err := svc.SendOverdueReminders(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := notifier.remindersFor(failing); len(got) != 1 {
+		t.Fatalf("failed reminder not retried: %+v", got)
+	}
+	if got := notifier.remindersFor(ok); len(got) != 1 {
+		t.Fatalf("sent reminder repeated: %+v", got)
+	}
+}
+
+func TestEscalateStaleReviews(t *testing.T) {
+	svc, cleanup := setupTestService(t)
+	defer cleanup()
+	h := NewHandler(svc)
+	ctx := context.Background()
+
+	team, prID, reviewer := setupSLATeam(t, svc, h)
+	if _, err := svc.SetTeamEscalationPolicy(ctx, team, true, 90, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	svc.SetClock(func() time.Time { return time.Now().Add(time.Hour) })
+	if err := svc.EscalateStaleReviews(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if history, _ := svc.ListReassignments(ctx, prID); len(history) != 0 {
+		t.Fatalf("escalated before escalate_after_minutes: %+v", history)
+	}
+
+	svc.SetClock(func() time.Time { return time.Now().Add(2 * time.Hour) })
+	if err := svc.EscalateStaleReviews(ctx); err != nil {
+		t.Fatal(err)
+	}
+	history, err := svc.ListReassignments(ctx, prID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].OldUserID != reviewer || history[0].Reason != models.ReasonEscalation {
+		t.Fatalf("expected one escalation of %s, got %+v", reviewer, history)
+	}
+
+	// The replacement is idle too by now, but max_auto_reassignments is 1.
+	svc.SetClock(func() time.Time { return time.Now().Add(4 * time.Hour) })
+	if err := svc.EscalateStaleReviews(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if history, _ := svc.ListReassignments(ctx, prID); len(history) != 1 {
+		t.Fatalf("max_auto_reassignments exceeded: %+v", history)
+	}
+}
+
+// failReassignments makes every reassignment of prID fail in the database
+// until the returned func is called. Calling it again does nothing.
+func failReassignments(t *testing.T, prID string) func() {
+	t.Helper()
+	db, err := sql.Open("pgx", testDatabaseURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trigger := fmt.Sprintf("%q", uniqueID("fail_reassignment"))
+	stmts := []string{
+		"CREATE OR REPLACE FUNCTION fail_reassignment() RETURNS trigger LANGUAGE plpgsql AS $$ BEGIN RAISE EXCEPTION 'reassignment failed'; END $$",
+		fmt.Sprintf("CREATE TRIGGER %s BEFORE INSERT ON pr_reassignments FOR EACH ROW WHEN (NEW.pull_request_id = '%s') EXECUTE FUNCTION fail_reassignment()", trigger, prID),
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			t.Fatal(err)
+		}
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			db.Exec("DROP TRIGGER " + trigger + " ON pr_reassignments")
+			db.Close()
+		})
+	}
+}
+
+func TestEscalateStaleReviewsContinuesPastFailure(t *testing.T) {
+	svc, cleanup := setupTestService(t)
+	defer cleanup()
+	h := NewHandler(svc)
+	ctx := context.Background()
+
+	var prIDs [2]string
+	for i := range prIDs {
+		var team string
+		team, prIDs[i], _ = setupSLATeam(t, svc, h)
+		if _, err := svc.SetTeamEscalationPolicy(ctx, team, true, 90, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	failing, ok := prIDs[0], prIDs[1]
+	restore := failReassignments(t, failing)
+	defer restore()
+
+	svc.SetClock(func() time.Time { return time.Now().Add(2 * time.Hour) })
+	if err := svc.EscalateStaleReviews(ctx); err == nil {
+		t.Fatal("failed escalation not reported")
+	}
+	if history, _ := svc.ListReassignments(ctx, ok); len(history) != 1 {
+		t.Fatalf("escalation after the failing one not done: %+v", history)
+	}
+
+	restore()
+	if err := svc.EscalateStaleReviews(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if history, _ := svc.ListReassignments(ctx, failing); len(history) != 1 {
+		t.Fatalf("failed escalation not retried: %+v", history)
+	}
+}
@@ -0,0 +1,21 @@
+package api
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Trace starts a server span named after the route for every request,
+// continuing the trace of an incoming W3C traceparent header.
+func Trace(next http.Handler, tp trace.TracerProvider) http.Handler {
+	return otelhttp.NewHandler(next, "prsvc",
+		otelhttp.WithTracerProvider(tp),
+		otelhttp.WithPropagators(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})),
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method + " " + routeName(r)
+		}),
+	)
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+	"github.com/Guardian1221/prsvc/internal/service"
+	"github.com/Guardian1221/prsvc/internal/workhours"
+)
+
+// userView shows a user's working hours in the format
+// /users/setWorkingHours accepts.
+type userView struct {
+	*models.User
+	WorkStart string   `json:"work_start"`
+	WorkEnd   string   `json:"work_end"`
+	WorkDays  []string `json:"work_days"`
+}
+
+func newUserView(u *models.User) userView {
+	return userView{
+		User:      u,
+		WorkStart: workhours.FormatClock(u.WorkStartMinute),
+		WorkEnd:   workhours.FormatClock(u.WorkEndMinute),
+		WorkDays:  workhours.Days(u.WorkDays).Names(),
+	}
+}
+
+type maxOpenReviewsReq struct {
+	UserID         string `json:"user_id"`
+	MaxOpenReviews *int   `json:"max_open_reviews"`
+}
+
+func (h *Handler) handleUserSetMaxOpenReviews(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := h.withTimeoutContext(r)
+	defer cancel()
+
+	var req maxOpenReviewsReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "invalid json")
+		return
+	}
+	if req.UserID == "" {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "user_id required")
+		return
+	}
+
+	u, err := h.svc.SetUserMaxOpenReviews(ctx, req.UserID, req.MaxOpenReviews)
+	if err != nil {
+		if err == service.ErrInvalidCapacity {
+			writeErrorJSON(w, http.StatusBadRequest, "INVALID_CAPACITY", "max_open_reviews must not be negative")
+			return
+		}
+		if err == sql.ErrNoRows {
+			writeErrorJSON(w, http.StatusNotFound, "NOT_FOUND", "user not found")
+			return
+		}
+		slog.ErrorContext(ctx, "SetUserMaxOpenReviews failed", "err", err)
+		writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"user": newUserView(u)})
+}
+
+type workingHoursReq struct {
+	UserID    string   `json:"user_id"`
+	TimeZone  string   `json:"time_zone"`
+	WorkStart string   `json:"work_start"`
+	WorkEnd   string   `json:"work_end"`
+	WorkDays  []string `json:"work_days"`
+}
+
+func (h *Handler) handleUserSetWorkingHours(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := h.withTimeoutContext(r)
+	defer cancel()
+
+	var req workingHoursReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "invalid json")
+		return
+	}
+	if req.UserID == "" || req.TimeZone == "" {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "user_id and time_zone required")
+		return
+	}
+	start, err := workhours.ParseClock(req.WorkStart)
+	if err != nil {
+		writeErrorJSON(w, http.StatusBadRequest, "INVALID_WORKING_HOURS", err.Error())
+		return
+	}
+	end, err := workhours.ParseClock(req.WorkEnd)
+	if err != nil {
+		writeErrorJSON(w, http.StatusBadRequest, "INVALID_WORKING_HOURS", err.Error())
+		return
+	}
+	days := workhours.Weekdays
+	if req.WorkDays != nil {
+		if days, err = workhours.ParseDays(req.WorkDays); err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, "INVALID_WORKING_HOURS", err.Error())
+			return
+		}
+	}
+
+	u, err := h.svc.SetUserWorkingHours(ctx, req.UserID, models.WorkingHours{
+		TimeZone:        req.TimeZone,
+		WorkStartMinute: start,
+		WorkEndMinute:   end,
+		WorkDays:        int(days),
+	})
+	if err != nil {
+		if err == service.ErrInvalidWorkingHours {
+			writeErrorJSON(w, http.StatusBadRequest, "INVALID_WORKING_HOURS", "time_zone must be an IANA zone, work_start before work_end, and at least one work day")
+			return
+		}
+		if err == sql.ErrNoRows {
+			writeErrorJSON(w, http.StatusNotFound, "NOT_FOUND", "user not found")
+			return
+		}
+		slog.ErrorContext(ctx, "SetUserWorkingHours failed", "err", err)
+		writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"user": newUserView(u)})
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+)
+
+func TestSetWorkingHoursTimeZones(t *testing.T) {
+	svc, cleanup := setupTestService(t)
+	defer cleanup()
+	h := NewHandler(svc)
+
+	user := uniqueID("user")
+	w := doJSON(t, h, http.MethodPost, "/team/add", models.Team{TeamName: uniqueID("team"), Members: []models.TeamMember{{UserID: user, Username: "U", IsActive: true}}})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("/team/add: %s", w.Body)
+	}
+
+	for _, tz := range []string{"Local", "Mars/Olympus_Mons"} {
+		w = doJSON(t, h, http.MethodPost, "/users/setWorkingHours", workingHoursReq{UserID: user, TimeZone: tz, WorkStart: "09:00", WorkEnd: "17:00"})
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "INVALID_WORKING_HOURS") {
+			t.Errorf("time_zone %q: %d %s", tz, w.Code, w.Body)
+		}
+	}
+
+	w = doJSON(t, h, http.MethodPost, "/users/setWorkingHours", workingHoursReq{UserID: user, TimeZone: "Europe/Berlin", WorkStart: "09:00", WorkEnd: "17:00"})
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Europe/Berlin") {
+		t.Fatalf("valid zone: %d %s", w.Code, w.Body)
+	}
+	// Selection converts now() into every candidate's zone, so a stored
+	// zone must not break staffing.
+	w = doJSON(t, h, http.MethodPost, "/pullRequest/create", createPRReq{PullRequestID: uniqueID("pr"), PullRequestName: "Zones", AuthorID: user})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create after setting the zone: %d %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Guardian1221/prsvc/internal/auth"
+	"github.com/Guardian1221/prsvc/internal/repo"
+	"github.com/Guardian1221/prsvc/internal/service"
+)
+
+type verdictReq struct {
+	PullRequestID string `json:"pull_request_id"`
+	UserID        string `json:"user_id"`
+}
+
+func (h *Handler) handlePRVerdict(w http.ResponseWriter, r *http.Request, verdict string) {
+	ctx, cancel := h.withTimeoutContext(r)
+	defer cancel()
+
+	var req verdictReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "invalid json")
+		return
+	}
+	req.PullRequestID = pathParam(r, "id", req.PullRequestID)
+	reviewer, ok := verdictReviewer(auth.FromContext(ctx), req.UserID)
+	if !ok {
+		writeErrorJSON(w, http.StatusForbidden, "FORBIDDEN", "only admin tokens may submit a verdict for another user")
+		return
+	}
+	if req.PullRequestID == "" || reviewer == "" {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "missing fields")
+		return
+	}
+
+	pr, err := h.svc.SubmitVerdict(ctx, req.PullRequestID, reviewer, verdict)
+	if err != nil {
+		switch err {
+		case repo.ErrPRMerged:
+			writeErrorJSON(w, http.StatusConflict, "PR_MERGED", "cannot review a merged PR")
+		case repo.ErrPRNotOpen:
+			writeErrorJSON(w, http.StatusConflict, "PR_NOT_OPEN", "cannot review a draft or closed PR")
+		case repo.ErrNotAssigned:
+			writeErrorJSON(w, http.StatusConflict, "NOT_ASSIGNED", "reviewer is not assigned to this PR")
+		case sql.ErrNoRows:
+			writeErrorJSON(w, http.StatusNotFound, "NOT_FOUND", "pr not found")
+		default:
+			slog.ErrorContext(ctx, "SubmitVerdict failed", "err", err)
+			writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"pr": pr})
+}
+
+// verdictReviewer is the user a verdict is submitted as. A user token
+// reviews as its own user, so user_id may be left out; admin tokens and
+// unauthenticated test setups name the reviewer in user_id. It is false
+// when a user token names someone else or is not bound to a user.
+func verdictReviewer(p *auth.Principal, userID string) (string, bool) {
+	if p == nil || p.Scope == auth.ScopeAdmin {
+		return userID, true
+	}
+	if p.UserID == "" || (userID != "" && userID != p.UserID) {
+		return "", false
+	}
+	return p.UserID, true
+}
+
+type prActionReq struct {
+	PullRequestID string `json:"pull_request_id"`
+}
+
+func (h *Handler) handlePRMerge(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := h.withTimeoutContext(r)
+	defer cancel()
+
+	var req prActionReq
+	if err := decodeBody(r, &req); err != nil {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "invalid json")
+		return
+	}
+	req.PullRequestID = pathParam(r, "id", req.PullRequestID)
+	if req.PullRequestID == "" {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "missing fields")
+		return
+	}
+
+	pr, err := h.svc.MergePullRequest(ctx, req.PullRequestID)
+	if err != nil {
+		switch err {
+		case repo.ErrInvalidTransition, repo.ErrPRNotOpen:
+			writeErrorJSON(w, http.StatusConflict, "INVALID_TRANSITION", "only an OPEN PR can be merged")
+		case repo.ErrNotEnoughApprovals:
+			writeErrorJSON(w, http.StatusConflict, "NOT_ENOUGH_APPROVALS", "PR does not have the approvals its team requires")
+		case sql.ErrNoRows:
+			writeErrorJSON(w, http.StatusNotFound, "NOT_FOUND", "pr not found")
+		default:
+			slog.ErrorContext(ctx, "MergePullRequest failed", "err", err)
+			writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"pr": pr})
+}
+
+type requiredApprovalsReq struct {
+	TeamName          string `json:"team_name"`
+	RequiredApprovals int    `json:"required_approvals"`
+}
+
+func (h *Handler) handleTeamSetRequiredApprovals(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := h.withTimeoutContext(r)
+	defer cancel()
+
+	var req requiredApprovalsReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "invalid json")
+		return
+	}
+	if req.TeamName == "" {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "team_name required")
+		return
+	}
+
+	t, err := h.svc.SetTeamRequiredApprovals(ctx, req.TeamName, req.RequiredApprovals)
+	if err != nil {
+		if err == service.ErrInvalidApprovals {
+			writeErrorJSON(w, http.StatusBadRequest, "INVALID_POLICY", "required_approvals is out of range")
+			return
+		}
+		if err == sql.ErrNoRows {
+			writeErrorJSON(w, http.StatusNotFound, "NOT_FOUND", "team not found")
+			return
+		}
+		slog.ErrorContext(ctx, "SetTeamRequiredApprovals failed", "err", err)
+		writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"team": t})
+}
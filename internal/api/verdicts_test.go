@@ -0,0 +1,167 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Guardian1221/prsvc/internal/auth"
+	"github.com/Guardian1221/prsvc/internal/models"
+)
+
+// setupVerdictPR opens a PR reviewed by both r1 and r2 in a team that needs
+// two approvals to merge.
+func setupVerdictPR(t *testing.T, h http.Handler) (prID, r1, r2, outsider string) {
+	t.Helper()
+	team, author, r1, r2, outsider := setupExclusionTeam(t, h, 2)
+	w := doJSON(t, h, http.MethodPost, "/team/setRequiredApprovals", requiredApprovalsReq{TeamName: team, RequiredApprovals: 2})
+	if w.Code != http.StatusOK {
+		t.Fatalf("/team/setRequiredApprovals: %s", w.Body)
+	}
+	prID = uniqueID("pr")
+	created := createPR(t, h, createPRReq{PullRequestID: prID, PullRequestName: "Verdicts", AuthorID: author})
+	if len(created.PR.AssignedReviewers) != 2 {
+		t.Fatalf("expected two reviewers, got %v", created.PR.AssignedReviewers)
+	}
+	return prID, r1, r2, outsider
+}
+
+func submitVerdict(t *testing.T, h http.Handler, path, prID, userID string) *httptest.ResponseRecorder {
+	t.Helper()
+	return doJSON(t, h, http.MethodPost, path, verdictReq{PullRequestID: prID, UserID: userID})
+}
+
+// submitVerdictAs submits a verdict with a bearer token, so the reviewer is
+// checked against the token's user.
+func submitVerdictAs(t *testing.T, h http.Handler, token, prID, userID string) *httptest.ResponseRecorder {
+	t.Helper()
+	b, err := json.Marshal(verdictReq{PullRequestID: prID, UserID: userID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/pullRequest/approve", bytes.NewReader(b))
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func decodePR(t *testing.T, w *httptest.ResponseRecorder) models.PullRequest {
+	t.Helper()
+	if w.Code != http.StatusOK {
+		t.Fatalf("%d %s", w.Code, w.Body)
+	}
+	var resp struct {
+		PR models.PullRequest `json:"pr"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp.PR
+}
+
+func verdictOf(pr models.PullRequest, userID string) *models.Verdict {
+	for i := range pr.Verdicts {
+		if pr.Verdicts[i].UserID == userID {
+			return &pr.Verdicts[i]
+		}
+	}
+	return nil
+}
+
+func TestSubmitVerdicts(t *testing.T) {
+	svc, cleanup := setupTestService(t)
+	defer cleanup()
+	h := NewHandler(svc)
+
+	prID, r1, r2, outsider := setupVerdictPR(t, h)
+
+	pr := decodePR(t, submitVerdict(t, h, "/pullRequest/approve", prID, r1))
+	approved := verdictOf(pr, r1)
+	if approved == nil || approved.Verdict != models.VerdictApproved || approved.CreatedAt.IsZero() || approved.UpdatedAt.Before(approved.CreatedAt) {
+		t.Fatalf("approval not stored: %+v", pr.Verdicts)
+	}
+
+	pr = decodePR(t, submitVerdict(t, h, "/pullRequest/requestChanges", prID, r1))
+	changed := verdictOf(pr, r1)
+	if len(pr.Verdicts) != 1 || changed.Verdict != models.VerdictChangesRequested {
+		t.Fatalf("changes requested did not replace the approval: %+v", pr.Verdicts)
+	}
+	if !changed.CreatedAt.Equal(approved.CreatedAt) || !changed.UpdatedAt.After(approved.UpdatedAt) {
+		t.Fatalf("replaced verdict %+v, was %+v", changed, approved)
+	}
+
+	w := submitVerdict(t, h, "/pullRequest/approve", prID, outsider)
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "NOT_ASSIGNED") {
+		t.Fatalf("non-reviewer verdict: %d %s", w.Code, w.Body)
+	}
+	w = submitVerdict(t, h, "/pullRequest/approve", uniqueID("missing"), r1)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("verdict on a missing PR: %d %s", w.Code, w.Body)
+	}
+
+	// A user token approves as its own user without naming it; an admin
+	// token may name any reviewer.
+	authed := RequireAuth(h, fakeAuthenticator{
+		"r1":    {Subject: "jwt:" + r1, Scope: auth.ScopeUser, UserID: r1},
+		"admin": {Subject: "token:admin", Scope: auth.ScopeAdmin},
+	})
+	pr = decodePR(t, submitVerdictAs(t, authed, "r1", prID, ""))
+	if v := verdictOf(pr, r1); v == nil || v.Verdict != models.VerdictApproved {
+		t.Fatalf("approval with r1's token: %+v", pr.Verdicts)
+	}
+	pr = decodePR(t, submitVerdictAs(t, authed, "admin", prID, r2))
+	if v := verdictOf(pr, r2); v == nil || v.Verdict != models.VerdictApproved {
+		t.Fatalf("approval on r2's behalf: %+v", pr.Verdicts)
+	}
+}
+
+func TestMergeNeedsApprovals(t *testing.T) {
+	svc, cleanup := setupTestService(t)
+	defer cleanup()
+	h := NewHandler(svc)
+
+	prID, r1, r2, _ := setupVerdictPR(t, h)
+	merge := func() *httptest.ResponseRecorder {
+		return doJSON(t, h, http.MethodPost, "/pullRequest/merge", prActionReq{PullRequestID: prID})
+	}
+
+	if w := merge(); w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "NOT_ENOUGH_APPROVALS") {
+		t.Fatalf("merge without approvals: %d %s", w.Code, w.Body)
+	}
+	decodePR(t, submitVerdict(t, h, "/pullRequest/approve", prID, r1))
+	decodePR(t, submitVerdict(t, h, "/pullRequest/requestChanges", prID, r2))
+	if w := merge(); w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "NOT_ENOUGH_APPROVALS") {
+		t.Fatalf("merge with changes requested: %d %s", w.Code, w.Body)
+	}
+
+	decodePR(t, submitVerdict(t, h, "/pullRequest/approve", prID, r2))
+	if merged := decodePR(t, merge()); merged.Status != models.StatusMerged {
+		t.Fatalf("merge with two approvals: %+v", merged)
+	}
+	w := submitVerdict(t, h, "/pullRequest/approve", prID, r1)
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "PR_MERGED") {
+		t.Fatalf("verdict after merge: %d %s", w.Code, w.Body)
+	}
+}
+
+func TestVerdictOnlyAsTokenUser(t *testing.T) {
+	h := RequireAuth(NewHandler(nil), fakeAuthenticator{
+		"alice":   {Subject: "jwt:alice", Scope: auth.ScopeUser, UserID: "alice"},
+		"service": {Subject: "token:ci", Scope: auth.ScopeUser},
+	})
+
+	for _, c := range []struct{ token, userID string }{
+		{"alice", "bob"},
+		{"service", "bob"},
+		{"service", ""},
+	} {
+		w := submitVerdictAs(t, h, c.token, "pr-1", c.userID)
+		if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "FORBIDDEN") {
+			t.Errorf("%s approving as %q: %d %s", c.token, c.userID, w.Code, w.Body)
+		}
+	}
+}
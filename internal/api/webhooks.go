@@ -0,0 +1,102 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/Guardian1221/prsvc/internal/vcs"
+)
+
+type mapUserReq struct {
+	Provider   string `json:"provider"`
+	ExternalID string `json:"external_id"`
+	UserID     string `json:"user_id"`
+}
+
+func (h *Handler) handleVCSMapUser(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := h.withTimeoutContext(r)
+	defer cancel()
+
+	var req mapUserReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "invalid json")
+		return
+	}
+	if req.Provider == "" || req.ExternalID == "" || req.UserID == "" {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "missing fields")
+		return
+	}
+	if !vcs.KnownProvider(req.Provider) {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "unknown provider")
+		return
+	}
+
+	if err := h.svc.SetExternalUser(ctx, req.Provider, req.ExternalID, req.UserID); err != nil {
+		if err == sql.ErrNoRows {
+			writeErrorJSON(w, http.StatusNotFound, "NOT_FOUND", "user not found")
+			return
+		}
+		slog.ErrorContext(ctx, "SetExternalUser failed", "err", err)
+		writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"mapping": req})
+}
+
+// handleGitLabWebhook is only served once WithGitLabWebhook set a secret.
+func (h *Handler) handleGitLabWebhook(w http.ResponseWriter, r *http.Request) {
+	if h.gitlabSecret == "" {
+		markUnmatched(r)
+		http.NotFound(w, r)
+		return
+	}
+	ctx, cancel := h.withTimeoutContext(r)
+	defer cancel()
+
+	if err := vcs.VerifyGitLabToken(r.Header, h.gitlabSecret); err != nil {
+		writeErrorJSON(w, http.StatusUnauthorized, "UNAUTHORIZED", "invalid webhook token")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "invalid body")
+		return
+	}
+	ev, err := vcs.ParseGitLabEvent(r.Header, body)
+	if err != nil {
+		writeErrorJSON(w, http.StatusBadRequest, "NOT_FOUND", "invalid payload")
+		return
+	}
+
+	h.applyVCSEvent(ctx, w, ev)
+}
+
+// applyVCSEvent answers 2xx for anything the host should not redeliver,
+// including events for authors nobody has mapped yet.
+func (h *Handler) applyVCSEvent(ctx context.Context, w http.ResponseWriter, ev vcs.Event) {
+	outcome, pr, err := h.vcs.Apply(ctx, ev)
+	if err != nil {
+		if err == vcs.ErrUserNotMapped {
+			slog.WarnContext(ctx, "webhook author not mapped, skipping", "provider", ev.Provider, "author", ev.AuthorExternalID, "pull_request_id", ev.PullRequestID)
+			outcome = vcs.OutcomeIgnored
+		} else {
+			slog.ErrorContext(ctx, "webhook failed", "provider", ev.Provider, "err", err)
+			writeErrorJSON(w, http.StatusInternalServerError, "NOT_FOUND", "internal error")
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	resp := map[string]any{"status": outcome}
+	if pr != nil {
+		resp["pr"] = pr
+	}
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+	"github.com/Guardian1221/prsvc/internal/vcs"
+)
+
+const testWebhookSecret = "hook-secret"
+
+// gitlabHook is the part of a merge request hook the tests vary.
+type gitlabHook struct {
+	project, iid int64
+	author       string
+	action       string
+	draft        bool
+	// leftDraft adds the changes GitLab sends when a draft is marked ready.
+	leftDraft bool
+}
+
+func sendGitLabHook(t *testing.T, h http.Handler, token, uuid string, hook gitlabHook) *httptest.ResponseRecorder {
+	t.Helper()
+	changes := ""
+	if hook.leftDraft {
+		changes = `, "changes": {"draft": {"previous": true, "current": false}}`
+	}
+	body := fmt.Sprintf(`{
+		"object_kind": "merge_request",
+		"project": {"id": %d},
+		"object_attributes": {"iid": %d, "title": "Hook", "author_id": %s, "action": %q, "draft": %t}%s
+	}`, hook.project, hook.iid, hook.author, hook.action, hook.draft, changes)
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/gitlab", strings.NewReader(body))
+	req.Header.Set("X-Gitlab-Token", token)
+	req.Header.Set("X-Gitlab-Event-UUID", uuid)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+type webhookResp struct {
+	Status vcs.Outcome         `json:"status"`
+	PR     *models.PullRequest `json:"pr"`
+}
+
+func decodeWebhook(t *testing.T, w *httptest.ResponseRecorder) webhookResp {
+	t.Helper()
+	if w.Code != http.StatusOK {
+		t.Fatalf("webhook: %d %s", w.Code, w.Body)
+	}
+	var resp webhookResp
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestGitLabWebhookRejectsBadToken(t *testing.T) {
+	h := NewHandler(nil, WithGitLabWebhook(testWebhookSecret))
+
+	for _, token := range []string{"", "wrong"} {
+		w := sendGitLabHook(t, h, token, "uuid", gitlabHook{project: 1, iid: 1, author: "1", action: "open"})
+		if w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), "UNAUTHORIZED") {
+			t.Errorf("token %q: %d %s", token, w.Code, w.Body)
+		}
+	}
+}
+
+func TestGitLabWebhookEvents(t *testing.T) {
+	svc, cleanup := setupTestService(t)
+	defer cleanup()
+	h := NewHandler(svc, WithGitLabWebhook(testWebhookSecret))
+
+	team, author := uniqueID("team"), uniqueID("author")
+	w := doJSON(t, h, http.MethodPost, "/team/add", models.Team{TeamName: team, Members: []models.TeamMember{
+		{UserID: author, Username: "Author", IsActive: true},
+		{UserID: uniqueID("reviewer"), Username: "Reviewer", IsActive: true},
+	}})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("/team/add: %s", w.Body)
+	}
+	gitlabUser := fmt.Sprint(time.Now().UnixNano() % 1e9)
+	w = doJSON(t, h, http.MethodPost, "/vcs/mapUser", mapUserReq{Provider: vcs.ProviderGitLab, ExternalID: gitlabUser, UserID: author})
+	if w.Code != http.StatusOK {
+		t.Fatalf("/vcs/mapUser: %s", w.Body)
+	}
+	project := time.Now().UnixNano() % 1e12
+	send := func(uuid string, iid int64, action string, hook gitlabHook) webhookResp {
+		t.Helper()
+		hook.project, hook.iid, hook.action = project, iid, action
+		if hook.author == "" {
+			hook.author = gitlabUser
+		}
+		return decodeWebhook(t, sendGitLabHook(t, h, testWebhookSecret, uuid, hook))
+	}
+
+	opened := send(uniqueID("open"), 1, "open", gitlabHook{})
+	if opened.Status != vcs.OutcomeApplied || opened.PR == nil || opened.PR.Status != models.StatusOpen || opened.PR.AuthorID != author {
+		t.Fatalf("open: %+v", opened)
+	}
+	if opened.PR.PullRequestID != vcs.GitLabPullRequestID(project, 1) {
+		t.Fatalf("open created %s", opened.PR.PullRequestID)
+	}
+
+	mergeUUID := uniqueID("merge")
+	if merged := send(mergeUUID, 1, "merge", gitlabHook{}); merged.Status != vcs.OutcomeApplied || merged.PR.Status != models.StatusMerged {
+		t.Fatalf("merge: %+v", merged)
+	}
+	if again := send(mergeUUID, 1, "merge", gitlabHook{}); again.Status != vcs.OutcomeDuplicate {
+		t.Fatalf("redelivered merge: %+v", again)
+	}
+
+	send(uniqueID("open"), 2, "open", gitlabHook{})
+	if closed := send(uniqueID("close"), 2, "close", gitlabHook{}); closed.Status != vcs.OutcomeApplied || closed.PR.Status != models.StatusClosed {
+		t.Fatalf("close: %+v", closed)
+	}
+	if reopened := send(uniqueID("reopen"), 2, "reopen", gitlabHook{}); reopened.Status != vcs.OutcomeApplied || reopened.PR.Status != models.StatusOpen {
+		t.Fatalf("reopen: %+v", reopened)
+	}
+
+	if draft := send(uniqueID("open"), 3, "open", gitlabHook{draft: true}); draft.PR.Status != models.StatusDraft {
+		t.Fatalf("draft open: %+v", draft)
+	}
+	ready := send(uniqueID("update"), 3, "update", gitlabHook{leftDraft: true})
+	if ready.Status != vcs.OutcomeApplied || ready.PR.Status != models.StatusOpen || len(ready.PR.AssignedReviewers) != 1 {
+		t.Fatalf("ready: %+v", ready)
+	}
+
+	if unmapped := send(uniqueID("open"), 4, "open", gitlabHook{author: "999999999999"}); unmapped.Status != vcs.OutcomeIgnored || unmapped.PR != nil {
+		t.Fatalf("unmapped author: %+v", unmapped)
+	}
+}
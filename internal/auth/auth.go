@@ -0,0 +1,75 @@
+// Package auth holds the caller identity of an API request, the API token
+// format and verification of SSO-issued JWTs.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+)
+
+// Scope is what a token may do. An admin token can do everything a user
+// token can.
+type Scope string
+
+const (
+	ScopeUser  Scope = "user"
+	ScopeAdmin Scope = "admin"
+)
+
+func (s Scope) Valid() bool {
+	return s == ScopeUser || s == ScopeAdmin
+}
+
+// Allows reports whether a caller with scope s may use a route that needs
+// required.
+func (s Scope) Allows(required Scope) bool {
+	switch required {
+	case ScopeUser:
+		return s == ScopeUser || s == ScopeAdmin
+	case ScopeAdmin:
+		return s == ScopeAdmin
+	}
+	return false
+}
+
+// Principal is the authenticated caller. UserID is empty for tokens that
+// are not bound to a prsvc user.
+type Principal struct {
+	Subject string `json:"subject"`
+	Scope   Scope  `json:"scope"`
+	UserID  string `json:"user_id,omitempty"`
+}
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// TokenPrefix marks prsvc tokens so they are easy to spot in leaked config.
+const TokenPrefix = "prsvc_"
+
+// NewToken returns a random bearer token. Only its Hash is stored.
+func NewToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return TokenPrefix + hex.EncodeToString(b), nil
+}
+
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+type ctxKey struct{}
+
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, ctxKey{}, p)
+}
+
+// FromContext returns the caller, or nil for unauthenticated requests.
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(ctxKey{}).(*Principal)
+	return p
+}
@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestScopeAllows(t *testing.T) {
+	cases := []struct {
+		have, need Scope
+		want       bool
+	}{
+		{ScopeAdmin, ScopeAdmin, true},
+		{ScopeAdmin, ScopeUser, true},
+		{ScopeUser, ScopeUser, true},
+		{ScopeUser, ScopeAdmin, false},
+		{Scope(""), ScopeUser, false},
+	}
+	for _, c := range cases {
+		if got := c.have.Allows(c.need); got != c.want {
+			t.Errorf("%q.Allows(%q) = %v, want %v", c.have, c.need, got, c.want)
+		}
+	}
+}
+
+func TestNewTokenIsRandomAndHashed(t *testing.T) {
+	a, err := NewToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == b || !strings.HasPrefix(a, TokenPrefix) {
+		t.Fatalf("tokens %q and %q", a, b)
+	}
+	if Hash(a) == a || Hash(a) != Hash(a) || Hash(a) == Hash(b) {
+		t.Fatal("hash is not a stable digest")
+	}
+}
+
+func TestContext(t *testing.T) {
+	if FromContext(context.Background()) != nil {
+		t.Fatal("empty context has a principal")
+	}
+	p := &Principal{Subject: "ci", Scope: ScopeUser}
+	if got := FromContext(NewContext(context.Background(), p)); got != p {
+		t.Fatalf("got %+v", got)
+	}
+}
@@ -0,0 +1,208 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// KeySource looks up the public key a JWT was signed with by its kid.
+type KeySource interface {
+	Key(ctx context.Context, kid string) (crypto.PublicKey, error)
+}
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// ParseJWKS reads RSA and EC signing keys from a JSON Web Key Set. Keys of
+// other types and encryption keys are skipped.
+func ParseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("parse jwks: %w", err)
+	}
+	keys := map[string]crypto.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		var key crypto.PublicKey
+		var err error
+		switch k.Kty {
+		case "RSA":
+			key, err = k.rsa()
+		case "EC":
+			key, err = k.ec()
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("jwks key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+	return keys, nil
+}
+
+func (k jwk) rsa() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, err
+	}
+	if len(n) == 0 || len(e) == 0 || len(e) > 4 {
+		return nil, errors.New("invalid RSA key")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+}
+
+func (k jwk) ec() (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch k.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+	}
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := base64.RawURLEncoding.DecodeString(k.Y)
+	if err != nil {
+		return nil, err
+	}
+	key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+	if !curve.IsOnCurve(key.X, key.Y) {
+		return nil, errors.New("EC point not on curve")
+	}
+	return key, nil
+}
+
+// StaticKeys is a key set that never changes, e.g. one read from a file.
+type StaticKeys map[string]crypto.PublicKey
+
+func LoadJWKSFile(path string) (StaticKeys, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		return nil, err
+	}
+	return StaticKeys(keys), nil
+}
+
+func (s StaticKeys) Key(_ context.Context, kid string) (crypto.PublicKey, error) {
+	if k, ok := s[kid]; ok {
+		return k, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+// RemoteKeys fetches a key set over HTTP and refetches it when a token
+// names an unknown kid, at most once per MinRefresh. Known keys are served
+// without waiting for a refetch in progress.
+type RemoteKeys struct {
+	URL        string
+	Client     *http.Client
+	MinRefresh time.Duration
+
+	mu   sync.RWMutex
+	keys map[string]crypto.PublicKey
+
+	// refresh serializes fetches and guards fetched.
+	refresh sync.Mutex
+	fetched time.Time
+}
+
+func NewRemoteKeys(url string) *RemoteKeys {
+	return &RemoteKeys{URL: url, Client: &http.Client{Timeout: 5 * time.Second}, MinRefresh: time.Minute}
+}
+
+func (r *RemoteKeys) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	if k, ok := r.lookup(kid); ok {
+		return k, nil
+	}
+
+	r.refresh.Lock()
+	defer r.refresh.Unlock()
+	// A fetch that finished while waiting may have brought the key.
+	if k, ok := r.lookup(kid); ok {
+		return k, nil
+	}
+	if !r.fetched.IsZero() && time.Since(r.fetched) < r.MinRefresh {
+		return nil, ErrUnknownKey
+	}
+	r.fetched = time.Now()
+	keys, err := r.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	r.keys = keys
+	r.mu.Unlock()
+	if k, ok := keys[kid]; ok {
+		return k, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+func (r *RemoteKeys) lookup(kid string) (crypto.PublicKey, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	k, ok := r.keys[kid]
+	return k, ok
+}
+
+func (r *RemoteKeys) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch jwks: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch jwks: status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("fetch jwks: %w", err)
+	}
+	return ParseJWKS(data)
+}
@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTVerifier accepts JWTs signed by a key from Keys. The UserClaim names
+// the prsvc user ID, and callers whose RolesClaim contains AdminRole get the
+// admin scope; everyone else gets user.
+type JWTVerifier struct {
+	Keys     KeySource
+	Issuer   string
+	Audience string
+
+	UserClaim  string
+	RolesClaim string
+	AdminRole  string
+}
+
+const (
+	DefaultUserClaim  = "sub"
+	DefaultRolesClaim = "roles"
+	DefaultAdminRole  = "prsvc-admin"
+)
+
+func NewJWTVerifier(keys KeySource, issuer, audience string) *JWTVerifier {
+	return &JWTVerifier{
+		Keys:       keys,
+		Issuer:     issuer,
+		Audience:   audience,
+		UserClaim:  DefaultUserClaim,
+		RolesClaim: DefaultRolesClaim,
+		AdminRole:  DefaultAdminRole,
+	}
+}
+
+// Authenticate verifies the signature, expiry, issuer and audience of token.
+// Every failure is reported as ErrInvalidToken.
+func (v *JWTVerifier) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512", "PS256", "PS384", "PS512"}),
+		jwt.WithExpirationRequired(),
+	}
+	if v.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(v.Issuer))
+	}
+	if v.Audience != "" {
+		opts = append(opts, jwt.WithAudience(v.Audience))
+	}
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.Keys.Key(ctx, kid)
+	}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	userID, _ := claims[v.UserClaim].(string)
+	if userID == "" {
+		return nil, fmt.Errorf("%w: missing %s claim", ErrInvalidToken, v.UserClaim)
+	}
+	p := &Principal{Subject: "jwt:" + userID, Scope: ScopeUser, UserID: userID}
+	for _, role := range stringList(claims[v.RolesClaim]) {
+		if role == v.AdminRole {
+			p.Scope = ScopeAdmin
+		}
+	}
+	return p, nil
+}
+
+// stringList accepts a claim given as a JSON array or a space separated
+// string.
+func stringList(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		res := make([]string, 0, len(v))
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				res = append(res, s)
+			}
+		}
+		return res
+	}
+	return nil
+}
+
+// Authenticator resolves a bearer token to its caller.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*Principal, error)
+}
+
+// WithJWT sends bearer tokens shaped like a JWT to v and all others to
+// tokens.
+func WithJWT(v *JWTVerifier, tokens Authenticator) Authenticator {
+	return jwtOrToken{jwt: v, tokens: tokens}
+}
+
+type jwtOrToken struct {
+	jwt    *JWTVerifier
+	tokens Authenticator
+}
+
+func (a jwtOrToken) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	if strings.Count(token, ".") == 2 {
+		return a.jwt.Authenticate(ctx, token)
+	}
+	return a.tokens.Authenticate(ctx, token)
+}
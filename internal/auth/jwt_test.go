@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func testKey(t *testing.T) (*rsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks, err := json.Marshal(map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": "k1",
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, jwks
+}
+
+func sign(t *testing.T, key *rsa.PrivateKey, claims jwt.MapClaims) string {
+	t.Helper()
+	tok := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	tok.Header["kid"] = "k1"
+	s, err := tok.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestJWTVerifier(t *testing.T) {
+	key, jwks := testKey(t)
+	keys, err := ParseJWKS(jwks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := NewJWTVerifier(StaticKeys(keys), "https://sso.example", "prsvc")
+	exp := time.Now().Add(time.Hour).Unix()
+
+	p, err := v.Authenticate(context.Background(), sign(t, key, jwt.MapClaims{
+		"sub": "u1", "iss": "https://sso.example", "aud": "prsvc", "exp": exp,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.UserID != "u1" || p.Scope != ScopeUser {
+		t.Fatalf("got %+v", p)
+	}
+
+	p, err = v.Authenticate(context.Background(), sign(t, key, jwt.MapClaims{
+		"sub": "u2", "iss": "https://sso.example", "aud": "prsvc", "exp": exp, "roles": []string{"dev", "prsvc-admin"},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Scope != ScopeAdmin {
+		t.Fatalf("admin role not mapped: %+v", p)
+	}
+
+	bad := []jwt.MapClaims{
+		{"sub": "u1", "iss": "https://sso.example", "aud": "prsvc", "exp": time.Now().Add(-time.Minute).Unix()},
+		{"sub": "u1", "iss": "https://sso.example", "aud": "other", "exp": exp},
+		{"sub": "u1", "iss": "https://evil.example", "aud": "prsvc", "exp": exp},
+		{"sub": "u1", "iss": "https://sso.example", "aud": "prsvc"},
+		{"iss": "https://sso.example", "aud": "prsvc", "exp": exp},
+	}
+	for _, claims := range bad {
+		if _, err := v.Authenticate(context.Background(), sign(t, key, claims)); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("claims %v: err = %v, want ErrInvalidToken", claims, err)
+		}
+	}
+
+	other, _ := testKey(t)
+	if _, err := v.Authenticate(context.Background(), sign(t, other, jwt.MapClaims{"sub": "u1", "exp": exp})); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("foreign key: err = %v", err)
+	}
+}
+
+func TestRemoteKeysRefetchesOnce(t *testing.T) {
+	key, jwks := testKey(t)
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Write(jwks)
+	}))
+	defer srv.Close()
+
+	rk := NewRemoteKeys(srv.URL)
+	v := NewJWTVerifier(rk, "", "")
+	tok := sign(t, key, jwt.MapClaims{"sub": "u1", "exp": time.Now().Add(time.Hour).Unix()})
+	for i := 0; i < 3; i++ {
+		if _, err := v.Authenticate(context.Background(), tok); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := rk.Key(context.Background(), "missing"); err != ErrUnknownKey {
+		t.Fatalf("missing kid: %v", err)
+	}
+	if n := hits.Load(); n != 1 {
+		t.Fatalf("fetched %d times, want 1", n)
+	}
+}
+
+func TestRemoteKeysServeKnownKeysDuringRefetch(t *testing.T) {
+	_, jwks := testKey(t)
+	var hits atomic.Int32
+	started, release := make(chan struct{}), make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) > 1 {
+			close(started)
+			<-release
+		}
+		w.Write(jwks)
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	rk := NewRemoteKeys(srv.URL)
+	rk.MinRefresh = 0
+	if _, err := rk.Key(context.Background(), "k1"); err != nil {
+		t.Fatal(err)
+	}
+
+	refetched := make(chan error, 1)
+	go func() {
+		_, err := rk.Key(context.Background(), "missing")
+		refetched <- err
+	}()
+	<-started
+
+	known := make(chan error, 1)
+	go func() {
+		_, err := rk.Key(context.Background(), "k1")
+		known <- err
+	}()
+	select {
+	case err := <-known:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("known key waited for the refetch")
+	}
+	release <- struct{}{}
+	if err := <-refetched; err != ErrUnknownKey {
+		t.Fatalf("missing kid: %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+// Package codeowners parses CODEOWNERS files and matches changed paths
+// against them using GitHub/GitLab semantics: patterns follow gitignore
+// rules and the last matching rule wins.
+package codeowners
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type Rule struct {
+	Pattern string
+	Owners  []string
+
+	re *regexp.Regexp
+}
+
+type File struct {
+	Rules []Rule
+}
+
+// Parse reads a CODEOWNERS file. GitLab section headers ("[Section]") are
+// skipped; their rules are treated like any other rule.
+func Parse(content string) (*File, error) {
+	f := &File{}
+	sc := bufio.NewScanner(strings.NewReader(content))
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		line := strings.TrimSpace(sc.Text())
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") || strings.HasPrefix(line, "^[") {
+			continue
+		}
+		fields := strings.Fields(line)
+		re, err := compile(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		f.Rules = append(f.Rules, Rule{Pattern: fields[0], Owners: fields[1:], re: re})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Owners returns the owners of path from the last matching rule, or nil.
+func (f *File) Owners(path string) []string {
+	path = strings.TrimPrefix(path, "/")
+	for i := len(f.Rules) - 1; i >= 0; i-- {
+		if f.Rules[i].re.MatchString(path) {
+			return f.Rules[i].Owners
+		}
+	}
+	return nil
+}
+
+// OwnerNames returns the distinct owners of all paths with the leading "@"
+// and any "org/" prefix stripped, in first-seen order. E-mail owners are
+// dropped since prsvc has no e-mail addresses.
+func (f *File) OwnerNames(paths []string) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, p := range paths {
+		for _, owner := range f.Owners(p) {
+			if !strings.HasPrefix(owner, "@") {
+				continue
+			}
+			name := strings.TrimPrefix(owner, "@")
+			if i := strings.LastIndex(name, "/"); i >= 0 {
+				name = name[i+1:]
+			}
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func compile(pattern string) (*regexp.Regexp, error) {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	p := strings.TrimSuffix(pattern, "/")
+	// A slash at the start or in the middle anchors the pattern to the
+	// repository root; otherwise it matches at any depth.
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+	if p == "" {
+		return nil, fmt.Errorf("empty pattern %q", pattern)
+	}
+
+	var sb strings.Builder
+	if anchored {
+		sb.WriteString("^")
+	} else {
+		sb.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	// A pattern naming a directory owns everything below it.
+	if dirOnly {
+		sb.WriteString("/.*$")
+	} else {
+		sb.WriteString("(?:/.*)?$")
+	}
+	return regexp.Compile(sb.String())
+}
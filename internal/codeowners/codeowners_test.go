@@ -0,0 +1,56 @@
+package codeowners
+
+import (
+	"reflect"
+	"testing"
+)
+
+const sample = `
+# default owners
+*                @lead
+
+*.go             @gopher @org/backend
+/docs/           @writer
+apps/**/api      @api-team
+[Frontend]
+web/*.js         frontend@example.com @web
+`
+
+func TestOwners(t *testing.T) {
+	f, err := Parse(sample)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	cases := []struct {
+		path string
+		want []string
+	}{
+		{"README.md", []string{"@lead"}},
+		{"internal/service/service.go", []string{"@gopher", "@org/backend"}},
+		{"docs/intro.md", []string{"@writer"}},
+		{"docs", []string{"@lead"}},
+		{"sub/docs/intro.md", []string{"@lead"}},
+		{"apps/billing/v1/api/handler.ts", []string{"@api-team"}},
+		{"apps/api/x", []string{"@api-team"}},
+		{"web/app.js", []string{"frontend@example.com", "@web"}},
+		{"web/lib/app.js", []string{"@lead"}},
+	}
+	for _, c := range cases {
+		if got := f.Owners(c.path); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Owners(%q) = %v, want %v", c.path, got, c.want)
+		}
+	}
+}
+
+func TestOwnerNames(t *testing.T) {
+	f, err := Parse(sample)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	got := f.OwnerNames([]string{"main.go", "web/app.js", "cmd/x.go"})
+	want := []string{"gopher", "backend", "web"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("OwnerNames = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func env(m map[string]string) func(string) string {
+	return func(k string) string { return m[k] }
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prsvc.yaml")
+	yaml := "http:\n  addr: :7000\n  request_timeout: 3s\ndatabase:\n  max_open_conns: 40\nshutdown:\n  timeout: 1m\n"
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := Load("prsvc", []string{"-config", path, "-http.addr", ":9000"}, env(map[string]string{
+		"HTTP_ADDR":         ":8000",
+		"DB_MAX_OPEN_CONNS": "50",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.HTTP.Addr != ":9000" {
+		t.Errorf("flag should win over env and file, got %q", c.HTTP.Addr)
+	}
+	if c.Database.MaxOpenConns != 50 {
+		t.Errorf("env should win over file, got %d", c.Database.MaxOpenConns)
+	}
+	if c.HTTP.RequestTimeout != 3*time.Second || c.Shutdown.Timeout != time.Minute {
+		t.Errorf("file values not applied: %+v %+v", c.HTTP, c.Shutdown)
+	}
+	if c.Database.MaxIdleConns != 5 || c.Readiness.Timeout != 2*time.Second {
+		t.Errorf("defaults not kept: %+v %+v", c.Database, c.Readiness)
+	}
+}
+
+func TestLoadFileFromEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prsvc.yaml")
+	if err := os.WriteFile(path, []byte("log:\n  level: debug\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c, err := Load("prsvc", nil, env(map[string]string{FileEnv: path}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Log.Level != "debug" {
+		t.Fatalf("got level %q", c.Log.Level)
+	}
+
+	if err := os.WriteFile(path, []byte("log:\n  levle: debug\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load("prsvc", nil, env(map[string]string{FileEnv: path})); err == nil {
+		t.Fatal("unknown key accepted")
+	}
+}
+
+func TestLoadRejectsInvalid(t *testing.T) {
+	for name, args := range map[string][]string{
+		"bad duration":   {"-http.read_timeout", "soon"},
+		"bad integer":    {"-database.max_open_conns", "many"},
+		"zero timeout":   {"-shutdown.timeout", "0s"},
+		"idle over open": {"-database.max_open_conns", "2", "-database.max_idle_conns", "3"},
+		"log level":      {"-log.level", "loud"},
+		"exporter":       {"-trace.exporter", "jaeger"},
+		"rate limit":     {"-rate_limit.default", "5/d"},
+		"both jwks":      {"-auth.jwt.jwks_file", "k.json", "-auth.jwt.jwks_url", "https://idp/keys"},
+		"positional":     {"serve"},
+	} {
+		if _, err := Load("prsvc", args, env(nil)); err == nil {
+			t.Errorf("%s: accepted %v", name, args)
+		}
+	}
+	if _, err := Load("prsvc", nil, env(map[string]string{"SHUTDOWN_DRAIN_DELAY": "-1s"})); err == nil {
+		t.Error("negative drain delay accepted")
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	c := Default()
+	c.Database.URL = "postgres://app:hunter2@db:5432/prsvc?sslmode=disable"
+	c.Auth.AdminToken = "admin-secret"
+	c.GitLab.WebhookSecret = "hook-secret"
+	c.GitHub.APIToken = "github-secret"
+
+	var buf bytes.Buffer
+	if err := c.Redacted().WriteYAML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, secret := range []string{"hunter2", "admin-secret", "hook-secret", "github-secret"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("%q printed:\n%s", secret, out)
+		}
+	}
+	if !strings.Contains(out, "postgres://app:REDACTED@db:5432/prsvc") {
+		t.Errorf("database url not readable:\n%s", out)
+	}
+	if !strings.Contains(out, `api_token: ""`) {
+		t.Errorf("empty secret should stay empty:\n%s", out)
+	}
+	if c.Auth.AdminToken != "admin-secret" {
+		t.Error("Redacted modified the original")
+	}
+
+	if got := redactURL("host=db password=hunter2"); got != redacted {
+		t.Errorf("key=value DSN: got %q", got)
+	}
+	if got := redactURL("postgres://db/prsvc?password=hunter2"); strings.Contains(got, "hunter2") {
+		t.Errorf("query password: got %q", got)
+	}
+}
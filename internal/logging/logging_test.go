@@ -0,0 +1,50 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestLoggerAddsRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, slog.LevelInfo)
+	logger.DebugContext(context.Background(), "dropped")
+	logger.With("component", "test").InfoContext(WithRequestID(context.Background(), "abc"), "hello", "n", 1)
+
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("not one JSON line: %q", buf.String())
+	}
+	if line["msg"] != "hello" || line["request_id"] != "abc" || line["component"] != "test" {
+		t.Fatalf("got %v", line)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	if l, err := ParseLevel("DEBUG"); err != nil || l != slog.LevelDebug {
+		t.Fatalf("got %v, %v", l, err)
+	}
+	if _, err := ParseLevel("loud"); err == nil {
+		t.Fatal("unknown level accepted")
+	}
+}
+
+func TestValidRequestID(t *testing.T) {
+	for id, want := range map[string]bool{
+		"abc-123":                 true,
+		"":                        false,
+		"has space":               false,
+		"line\nbreak":             false,
+		string(make([]byte, 129)): false,
+	} {
+		if got := ValidRequestID(id); got != want {
+			t.Errorf("ValidRequestID(%q) = %v", id, got)
+		}
+	}
+	if a, b := NewRequestID(), NewRequestID(); a == b || len(a) != 32 {
+		t.Fatalf("ids %q %q", a, b)
+	}
+}
@@ -0,0 +1,98 @@
+// Package metrics exposes prsvc's Prometheus metrics: HTTP traffic, the
+// database pool and domain events.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type Metrics struct {
+	reg *prometheus.Registry
+
+	requests *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+
+	prsCreated    prometheus.Counter
+	reassignments *prometheus.CounterVec
+	noCandidate   *prometheus.CounterVec
+	merges        *prometheus.CounterVec
+}
+
+// New registers all metrics on a fresh registry. db, when not nil, is
+// reported through its pool statistics.
+func New(db *sql.DB) *Metrics {
+	m := &Metrics{
+		reg: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "prsvc_http_requests_total",
+			Help: "HTTP requests by route, method and status code.",
+		}, []string{"route", "method", "status"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "prsvc_http_request_duration_seconds",
+			Help:    "HTTP request latency by route, method and status code.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		prsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "prsvc_pull_requests_created_total",
+			Help: "Pull requests created.",
+		}),
+		reassignments: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "prsvc_reassignments_total",
+			Help: "Reviewer reassignments by reason.",
+		}, []string{"reason"}),
+		noCandidate: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "prsvc_no_candidate_total",
+			Help: "Reassignments that failed for lack of a replacement, by reason.",
+		}, []string{"reason"}),
+		merges: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "prsvc_merges_total",
+			Help: "Pull requests merged, through the API or reported by the code host.",
+		}, []string{"source"}),
+	}
+	m.reg.MustRegister(m.requests, m.latency, m.prsCreated, m.reassignments, m.noCandidate, m.merges,
+		collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	if db != nil {
+		m.reg.MustRegister(collectors.NewDBStatsCollector(db, "prsvc"))
+	}
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.reg, promhttp.HandlerOpts{Registry: m.reg})
+}
+
+func (m *Metrics) ObserveRequest(route, method string, status int, elapsed time.Duration) {
+	code := strconv.Itoa(status)
+	m.requests.WithLabelValues(route, method, code).Inc()
+	m.latency.WithLabelValues(route, method, code).Observe(elapsed.Seconds())
+}
+
+func (m *Metrics) PRCreated() {
+	m.prsCreated.Inc()
+}
+
+func (m *Metrics) Reassigned(reason string) {
+	m.reassignments.WithLabelValues(reason).Inc()
+}
+
+func (m *Metrics) NoCandidate(reason string) {
+	m.noCandidate.WithLabelValues(reason).Inc()
+}
+
+// Merged counts a merge; external is set for merges reported by the code
+// host.
+func (m *Metrics) Merged(external bool) {
+	source := "api"
+	if external {
+		source = "external"
+	}
+	m.merges.WithLabelValues(source).Inc()
+}
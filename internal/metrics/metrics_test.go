@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandlerExposesMetrics(t *testing.T) {
+	m := New(nil)
+	m.ObserveRequest("/pullRequest/create", "POST", 201, 30*time.Millisecond)
+	m.PRCreated()
+	m.Reassigned("MANUAL")
+	m.NoCandidate("ESCALATION")
+	m.Merged(true)
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(w.Body)
+	for _, want := range []string{
+		`prsvc_http_requests_total{method="POST",route="/pullRequest/create",status="201"} 1`,
+		`prsvc_http_request_duration_seconds_count{method="POST",route="/pullRequest/create",status="201"} 1`,
+		`prsvc_pull_requests_created_total 1`,
+		`prsvc_reassignments_total{reason="MANUAL"} 1`,
+		`prsvc_no_candidate_total{reason="ESCALATION"} 1`,
+		`prsvc_merges_total{source="external"} 1`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("missing %s", want)
+		}
+	}
+}
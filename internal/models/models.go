@@ -3,8 +3,33 @@ package models
 import "time"
 
 type Team struct {
-	TeamName string       `db:"team_name" json:"team_name"`
-	Members  []TeamMember `json:"members"`
+	TeamName     string `db:"team_name" json:"team_name"`
+	MinReviewers int    `db:"min_reviewers" json:"min_reviewers"`
+	MaxReviewers int    `db:"max_reviewers" json:"max_reviewers"`
+	// RequiredApprovals is how many APPROVED verdicts a member's PR needs
+	// before it can be merged.
+	RequiredApprovals int `db:"required_approvals" json:"required_approvals"`
+	// ReviewSLAMinutes is how long a reviewer has for a first response, in
+	// the reviewer's working minutes; the default of 540 is one 9 to 18
+	// working day.
+	ReviewSLAMinutes int `db:"review_sla_minutes" json:"review_sla_minutes"`
+	// With AutoEscalate, a review idle for EscalateAfterMinutes of the
+	// reviewer's working time is reassigned automatically, at most
+	// MaxAutoReassignments times per PR.
+	AutoEscalate         bool `db:"auto_escalate" json:"auto_escalate"`
+	EscalateAfterMinutes int  `db:"escalate_after_minutes" json:"escalate_after_minutes"`
+	MaxAutoReassignments int  `db:"max_auto_reassignments" json:"max_auto_reassignments"`
+	// PairingWindowDays makes selection prefer reviewers who reviewed the
+	// author less often in that many days; 0 turns that off.
+	PairingWindowDays int `db:"pairing_window_days" json:"pairing_window_days"`
+	// RequiredLevelCount reviewers of each PR must be at RequiredLevel or
+	// above.
+	RequiredLevel      string `db:"required_level" json:"required_level"`
+	RequiredLevelCount int    `db:"required_level_count" json:"required_level_count"`
+	// FallbackTeams lend reviewers, in order, when the team cannot staff
+	// a PR on its own.
+	FallbackTeams []string     `json:"fallback_teams"`
+	Members       []TeamMember `json:"members"`
 }
 
 type TeamMember struct {
@@ -14,11 +39,27 @@ type TeamMember struct {
 }
 
 type User struct {
-	UserID    string    `db:"user_id" json:"user_id"`
-	Username  string    `db:"username" json:"username"`
-	TeamName  string    `db:"team_name" json:"team_name"`
-	IsActive  bool      `db:"is_active" json:"is_active"`
-	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UserID   string `db:"user_id" json:"user_id"`
+	Username string `db:"username" json:"username"`
+	TeamName string `db:"team_name" json:"team_name"`
+	IsActive bool   `db:"is_active" json:"is_active"`
+	// MaxOpenReviews caps how many OPEN PRs the user reviews at once; nil
+	// means no cap.
+	MaxOpenReviews *int      `db:"max_open_reviews" json:"max_open_reviews"`
+	Level          string    `db:"level" json:"level"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+	// WorkingHours are in the user's TimeZone.
+	WorkingHours
+}
+
+// WorkingHours is stored as minutes since local midnight and a weekday
+// bitmask with bit 0 for Sunday. The API shows them the way it accepts
+// them, as "HH:MM" and day names.
+type WorkingHours struct {
+	TimeZone        string `db:"time_zone" json:"time_zone"`
+	WorkStartMinute int    `db:"work_start_minute" json:"-"`
+	WorkEndMinute   int    `db:"work_end_minute" json:"-"`
+	WorkDays        int    `db:"work_days" json:"-"`
 }
 
 type PullRequest struct {
@@ -27,6 +68,173 @@ type PullRequest struct {
 	AuthorID          string     `db:"author_id" json:"author_id"`
 	Status            string     `db:"status" json:"status"`
 	AssignedReviewers []string   `json:"assigned_reviewers"`
+	Verdicts          []Verdict  `json:"verdicts"`
 	CreatedAt         time.Time  `db:"created_at" json:"createdAt,omitempty"`
 	MergedAt          *time.Time `db:"merged_at" json:"mergedAt,omitempty"`
+	ClosedAt          *time.Time `db:"closed_at" json:"closedAt,omitempty"`
+}
+
+const (
+	StatusDraft  = "DRAFT"
+	StatusOpen   = "OPEN"
+	StatusMerged = "MERGED"
+	StatusClosed = "CLOSED"
+)
+
+// Levels in ascending order of seniority.
+const (
+	LevelJunior = "JUNIOR"
+	LevelMid    = "MID"
+	LevelSenior = "SENIOR"
+)
+
+const (
+	VerdictApproved         = "APPROVED"
+	VerdictChangesRequested = "CHANGES_REQUESTED"
+)
+
+// Verdict is a reviewer's latest decision on a PR.
+type Verdict struct {
+	UserID    string    `db:"user_id" json:"user_id"`
+	Verdict   string    `db:"verdict" json:"verdict"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Staffing reports how a PR's reviewer assignment went against the policy
+// of the author's team.
+type Staffing struct {
+	MinReviewers int  `json:"min_reviewers"`
+	MaxReviewers int  `json:"max_reviewers"`
+	Understaffed bool `json:"understaffed"`
+	// FallbackReviewers are the assigned reviewers borrowed from fallback
+	// teams.
+	FallbackReviewers []string `json:"fallback_reviewers,omitempty"`
+	// CapacityLimited is set on an understaffed PR when eligible reviewers
+	// were skipped only because they were at max_open_reviews.
+	CapacityLimited bool `json:"capacity_limited"`
+	// LevelUnmet is set when fewer than the team's required_level_count
+	// reviewers at required_level could be assigned.
+	LevelUnmet bool `json:"level_unmet"`
+}
+
+const (
+	ReasonManual     = "manual"
+	ReasonAbsence    = "absence"
+	ReasonEscalation = "escalation"
+)
+
+// Reassignment is the outcome of replacing one reviewer on a PR.
+type Reassignment struct {
+	PR           *PullRequest
+	ReplacedBy   string
+	FromFallback bool
+	// LevelUnmet is set when the replaced reviewer counted towards the
+	// team's level requirement and no one at that level could take over.
+	LevelUnmet bool
+}
+
+// ReassignmentRecord is one entry of a PR's reassignment history.
+type ReassignmentRecord struct {
+	OldUserID string    `db:"old_user_id" json:"old_user_id"`
+	NewUserID string    `db:"new_user_id" json:"new_user_id"`
+	Reason    string    `db:"reason" json:"reason"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
 }
+
+// Absence is a window in which a user must not be picked as a reviewer.
+type Absence struct {
+	ID           int64      `db:"id" json:"id"`
+	UserID       string     `db:"user_id" json:"user_id"`
+	StartsAt     time.Time  `db:"starts_at" json:"starts_at"`
+	EndsAt       time.Time  `db:"ends_at" json:"ends_at"`
+	Reason       string     `db:"reason" json:"reason"`
+	AutoReassign bool       `db:"auto_reassign" json:"auto_reassign"`
+	ReassignedAt *time.Time `db:"reassigned_at" json:"reassigned_at,omitempty"`
+}
+
+// PendingReview is a reviewer assignment on an OPEN PR that has no verdict
+// yet, with the SLA of the author's team.
+type PendingReview struct {
+	PullRequestID   string     `db:"pull_request_id" json:"pull_request_id"`
+	PullRequestName string     `db:"pull_request_name" json:"pull_request_name"`
+	AuthorID        string     `db:"author_id" json:"author_id"`
+	ReviewerID      string     `db:"user_id" json:"reviewer_id"`
+	TeamName        string     `db:"team_name" json:"team_name"`
+	AssignedAt      time.Time  `db:"assigned_at" json:"assigned_at"`
+	RemindedAt      *time.Time `db:"reminded_at" json:"reminded_at,omitempty"`
+	SLAMinutes      int        `db:"review_sla_minutes" json:"sla_minutes"`
+
+	ReviewerTimeZone    string `db:"time_zone" json:"-"`
+	ReviewerStartMinute int    `db:"work_start_minute" json:"-"`
+	ReviewerEndMinute   int    `db:"work_end_minute" json:"-"`
+	ReviewerDays        int    `db:"work_days" json:"-"`
+
+	AutoEscalate         bool `db:"auto_escalate" json:"-"`
+	EscalateAfterMinutes int  `db:"escalate_after_minutes" json:"-"`
+	MaxAutoReassignments int  `db:"max_auto_reassignments" json:"-"`
+}
+
+// OverdueReview is a PendingReview past its SLA.
+type OverdueReview struct {
+	PendingReview
+	DueAt          time.Time `json:"due_at"`
+	OverdueMinutes int       `json:"overdue_minutes"`
+}
+
+// PairingStats measures how evenly authors' PRs are spread over reviewers in
+// the last WindowDays. Diversity is DistinctPairs / Assignments: 1 means no
+// author had the same reviewer twice.
+type PairingStats struct {
+	TeamName      string         `json:"team_name,omitempty"`
+	WindowDays    int            `json:"window_days"`
+	Assignments   int            `json:"assignments"`
+	DistinctPairs int            `json:"distinct_pairs"`
+	Diversity     float64        `json:"diversity"`
+	RepeatedPairs []PairingCount `json:"repeated_pairs"`
+}
+
+type PairingCount struct {
+	AuthorID   string `db:"author_id" json:"author_id"`
+	ReviewerID string `db:"reviewer_id" json:"reviewer_id"`
+	Reviews    int    `db:"reviews" json:"reviews"`
+}
+
+// ReviewerExclusion keeps ReviewerID from ever being picked for AuthorID's
+// PRs, e.g. a manager and their direct report.
+type ReviewerExclusion struct {
+	TeamName   string    `db:"team_name" json:"team_name"`
+	AuthorID   string    `db:"author_id" json:"author_id"`
+	ReviewerID string    `db:"reviewer_id" json:"reviewer_id"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
+
+// APIToken describes an issued bearer token; the token itself is only shown
+// once, when it is issued.
+type APIToken struct {
+	ID        int64      `db:"id" json:"id"`
+	Name      string     `db:"name" json:"name"`
+	Scope     string     `db:"scope" json:"scope"`
+	UserID    *string    `db:"user_id" json:"user_id,omitempty"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	RevokedAt *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+}
+
+// Readiness is the state of each dependency an instance needs to serve
+// traffic. Status is "ok" only when every check is.
+type Readiness struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+type CheckResult struct {
+	Status    string  `json:"status"`
+	Error     string  `json:"error,omitempty"`
+	LatencyMS float64 `json:"latency_ms"`
+	Version   *int64  `json:"version,omitempty"`
+}
+
+const (
+	CheckOK   = "ok"
+	CheckFail = "fail"
+)
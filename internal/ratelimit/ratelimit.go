@@ -0,0 +1,201 @@
+// Package ratelimit keeps a token bucket per client and route.
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limit allows Burst requests at once, refilled at Requests per Per.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+	Burst    int
+}
+
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+func (l Limit) String() string {
+	s := strconv.Itoa(l.Requests) + "/" + unitName(l.Per)
+	if l.Burst != l.Requests {
+		s += ":" + strconv.Itoa(l.Burst)
+	}
+	return s
+}
+
+func unitName(d time.Duration) string {
+	switch d {
+	case time.Second:
+		return "s"
+	case time.Minute:
+		return "m"
+	case time.Hour:
+		return "h"
+	}
+	return d.String()
+}
+
+// ParseLimit reads "<requests>/<s|m|h>[:<burst>]", e.g. "10/s" or "60/m:20".
+// The burst defaults to the request count.
+func ParseLimit(s string) (Limit, error) {
+	spec, burst, hasBurst := strings.Cut(strings.TrimSpace(s), ":")
+	n, unit, ok := strings.Cut(spec, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q", s)
+	}
+	var l Limit
+	var err error
+	if l.Requests, err = strconv.Atoi(n); err != nil || l.Requests <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q", s)
+	}
+	switch unit {
+	case "s":
+		l.Per = time.Second
+	case "m":
+		l.Per = time.Minute
+	case "h":
+		l.Per = time.Hour
+	default:
+		return Limit{}, fmt.Errorf("invalid rate limit unit in %q", s)
+	}
+	l.Burst = l.Requests
+	if hasBurst {
+		if l.Burst, err = strconv.Atoi(burst); err != nil || l.Burst <= 0 {
+			return Limit{}, fmt.Errorf("invalid burst in %q", s)
+		}
+	}
+	return l, nil
+}
+
+// ParseRoutes reads "path=limit" pairs separated by ";", e.g.
+// "/pullRequest/create=5/s;/team/add=10/m".
+func ParseRoutes(s string) (map[string]Limit, error) {
+	routes := map[string]Limit{}
+	for _, part := range strings.Split(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		path, spec, ok := strings.Cut(part, "=")
+		if !ok || !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("invalid route limit %q", part)
+		}
+		l, err := ParseLimit(spec)
+		if err != nil {
+			return nil, err
+		}
+		routes[path] = l
+	}
+	return routes, nil
+}
+
+// Result describes the bucket after a request was counted.
+type Result struct {
+	Allowed   bool
+	Limit     Limit
+	Remaining int
+	// Reset is when the bucket is full again; RetryAfter, for a rejected
+	// request, is when the next one will be let through.
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// bucketKey identifies a bucket. The route is empty for the shared default
+// bucket.
+type bucketKey struct {
+	client, route string
+}
+
+// Limiter holds one bucket per key. Routes not listed in Routes share a
+// bucket limited by Default.
+type Limiter struct {
+	Default Limit
+	Routes  map[string]Limit
+
+	mu      sync.Mutex
+	buckets map[bucketKey]*bucket
+	swept   time.Time
+}
+
+func New(def Limit, routes map[string]Limit) *Limiter {
+	return &Limiter{Default: def, Routes: routes, buckets: map[bucketKey]*bucket{}}
+}
+
+// Allow counts a request by client to route at now.
+func (l *Limiter) Allow(client, route string, now time.Time) Result {
+	return l.take(client, route, now, true)
+}
+
+// Peek reports whether Allow would let a request through, without counting
+// one.
+func (l *Limiter) Peek(client, route string, now time.Time) Result {
+	return l.take(client, route, now, false)
+}
+
+func (l *Limiter) take(client, route string, now time.Time, count bool) Result {
+	limit, ok := l.Routes[route]
+	if !ok {
+		limit, route = l.Default, ""
+	}
+	key := bucketKey{client, route}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		l.buckets[key] = b
+	}
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(limit.Burst), b.tokens+elapsed*limit.rate())
+		b.last = now
+	}
+
+	res := Result{Limit: limit}
+	if b.tokens >= 1 {
+		if count {
+			b.tokens--
+		}
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - b.tokens) / limit.rate())
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = seconds((float64(limit.Burst) - b.tokens) / limit.rate())
+	return res
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// sweep drops buckets idle long enough to be full again, so clients that
+// went away do not pile up.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < time.Minute {
+		return
+	}
+	l.swept = now
+	for key, b := range l.buckets {
+		limit, ok := l.Routes[key.route]
+		if !ok {
+			limit = l.Default
+		}
+		if now.Sub(b.last).Seconds()*limit.rate() >= float64(limit.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLimit(t *testing.T) {
+	l, err := ParseLimit("60/m:10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Requests != 60 || l.Per != time.Minute || l.Burst != 10 {
+		t.Fatalf("got %+v", l)
+	}
+	if l, _ := ParseLimit("5/s"); l.Burst != 5 || l.String() != "5/s" {
+		t.Fatalf("got %+v", l)
+	}
+	for _, bad := range []string{"", "5", "0/s", "5/d", "5/s:0", "x/s"} {
+		if _, err := ParseLimit(bad); err == nil {
+			t.Errorf("ParseLimit(%q) accepted", bad)
+		}
+	}
+
+	routes, err := ParseRoutes("/pullRequest/create=5/s; /team/add=10/m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 2 || routes["/team/add"].Per != time.Minute {
+		t.Fatalf("got %+v", routes)
+	}
+	if _, err := ParseRoutes("pullRequest=5/s"); err == nil {
+		t.Fatal("route without leading slash accepted")
+	}
+}
+
+func TestLimiterBuckets(t *testing.T) {
+	l := New(Limit{Requests: 10, Per: time.Second, Burst: 10}, map[string]Limit{
+		"/pullRequest/create": {Requests: 1, Per: time.Second, Burst: 2},
+	})
+	now := time.Unix(1000, 0)
+
+	for i := 0; i < 2; i++ {
+		if res := l.Allow("a", "/pullRequest/create", now); !res.Allowed {
+			t.Fatalf("request %d rejected", i)
+		}
+	}
+	res := l.Allow("a", "/pullRequest/create", now)
+	if res.Allowed || res.Remaining != 0 || res.RetryAfter != time.Second {
+		t.Fatalf("third request: %+v", res)
+	}
+
+	if !l.Allow("b", "/pullRequest/create", now).Allowed {
+		t.Fatal("other client shares the bucket")
+	}
+	if !l.Allow("a", "/team/get", now).Allowed {
+		t.Fatal("default route shares the route bucket")
+	}
+
+	if !l.Allow("a", "/pullRequest/create", now.Add(time.Second)).Allowed {
+		t.Fatal("bucket did not refill")
+	}
+}
+
+func TestLimiterPeek(t *testing.T) {
+	l := New(Limit{Requests: 1, Per: time.Second, Burst: 1}, nil)
+	now := time.Unix(1000, 0)
+
+	for i := 0; i < 3; i++ {
+		if !l.Peek("a", "", now).Allowed {
+			t.Fatalf("peek %d counted a request", i)
+		}
+	}
+	l.Allow("a", "", now)
+	if res := l.Peek("a", "", now); res.Allowed || res.RetryAfter != time.Second {
+		t.Fatalf("peek after the bucket emptied: %+v", res)
+	}
+}
+
+func TestLimiterSweepKeepsSubjectsWithSpaces(t *testing.T) {
+	l := New(Limit{Requests: 10, Per: time.Second, Burst: 10}, map[string]Limit{
+		"/team/add": {Requests: 1, Per: time.Hour, Burst: 1},
+	})
+	now := time.Unix(1000, 0)
+	client := "subject:jwt:Jane Doe"
+
+	l.Allow(client, "/team/add", now)
+	// Two minutes refill the default bucket but not the hourly one, so the
+	// sweep must see the route limit for this client's bucket.
+	if res := l.Allow(client, "/team/add", now.Add(2*time.Minute)); res.Allowed {
+		t.Fatalf("bucket swept early: %+v", res)
+	}
+}
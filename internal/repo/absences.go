@@ -0,0 +1,74 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+)
+
+const absenceColumns = "id, user_id, starts_at, ends_at, reason, auto_reassign, reassigned_at"
+
+func (r *PostgresRepo) AddAbsence(ctx context.Context, a models.Absence) (*models.Absence, error) {
+	var created models.Absence
+	err := r.db.GetContext(ctx, &created, `
+INSERT INTO user_absences(user_id, starts_at, ends_at, reason, auto_reassign)
+VALUES ($1,$2,$3,$4,$5)
+RETURNING `+absenceColumns, a.UserID, a.StartsAt, a.EndsAt, a.Reason, a.AutoReassign)
+	if err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (r *PostgresRepo) ListAbsences(ctx context.Context, userID string) ([]models.Absence, error) {
+	res := []models.Absence{}
+	if err := r.db.SelectContext(ctx, &res, "SELECT "+absenceColumns+" FROM user_absences WHERE user_id=$1 ORDER BY starts_at", userID); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (r *PostgresRepo) DeleteAbsence(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM user_absences WHERE id=$1", id)
+	if err != nil {
+		return err
+	}
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if cnt == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ListStartedAutoReassignAbsences returns absences that have begun, asked
+// for automatic reassignment and were not handled yet.
+func (r *PostgresRepo) ListStartedAutoReassignAbsences(ctx context.Context) ([]models.Absence, error) {
+	res := []models.Absence{}
+	if err := r.db.SelectContext(ctx, &res, "SELECT "+absenceColumns+" FROM user_absences WHERE auto_reassign AND reassigned_at IS NULL AND starts_at <= now() AND ends_at > now() ORDER BY starts_at"); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (r *PostgresRepo) MarkAbsenceReassigned(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE user_absences SET reassigned_at=now() WHERE id=$1", id)
+	return err
+}
+
+// ListOpenReviewsOf returns the IDs of OPEN PRs the user is assigned to.
+func (r *PostgresRepo) ListOpenReviewsOf(ctx context.Context, userID string) ([]string, error) {
+	res := []string{}
+	if err := r.db.SelectContext(ctx, &res, `
+SELECT pr.pull_request_id FROM pull_requests pr
+JOIN pr_reviewers rv ON rv.pull_request_id = pr.pull_request_id
+WHERE rv.user_id=$1 AND rv.released_at IS NULL AND pr.status='OPEN'
+ORDER BY pr.created_at
+`, userID); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,20 @@
+package repo
+
+import "context"
+
+func (r *PostgresRepo) SetCodeOwners(ctx context.Context, repository, content string) error {
+	_, err := r.db.ExecContext(ctx, `
+INSERT INTO codeowners(repository, content, updated_at)
+VALUES ($1,$2, now())
+ON CONFLICT (repository) DO UPDATE SET content = EXCLUDED.content, updated_at = EXCLUDED.updated_at
+`, repository, content)
+	return err
+}
+
+func (r *PostgresRepo) GetCodeOwners(ctx context.Context, repository string) (string, error) {
+	var content string
+	if err := r.db.GetContext(ctx, &content, "SELECT content FROM codeowners WHERE repository=$1", repository); err != nil {
+		return "", err
+	}
+	return content, nil
+}
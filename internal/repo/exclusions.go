@@ -0,0 +1,85 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+)
+
+var ErrInvalidExclusion = errors.New("invalid exclusion")
+
+// AddReviewerExclusion stores an exclusion pair for teamName. The author must
+// be a member of the team; the reviewer can be anyone.
+func (r *PostgresRepo) AddReviewerExclusion(ctx context.Context, x models.ReviewerExclusion) error {
+	if x.AuthorID == x.ReviewerID {
+		return ErrInvalidExclusion
+	}
+	var authorTeam string
+	if err := r.db.GetContext(ctx, &authorTeam, "SELECT team_name FROM users WHERE user_id=$1", x.AuthorID); err != nil {
+		return err
+	}
+	if authorTeam != x.TeamName {
+		return ErrInvalidExclusion
+	}
+	var exists bool
+	if err := r.db.GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM users WHERE user_id=$1)", x.ReviewerID); err != nil {
+		return err
+	}
+	if !exists {
+		return sql.ErrNoRows
+	}
+	_, err := r.db.ExecContext(ctx, "INSERT INTO reviewer_exclusions(team_name, author_id, reviewer_id) VALUES($1,$2,$3) ON CONFLICT DO NOTHING", x.TeamName, x.AuthorID, x.ReviewerID)
+	return err
+}
+
+func (r *PostgresRepo) DeleteReviewerExclusion(ctx context.Context, x models.ReviewerExclusion) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM reviewer_exclusions WHERE team_name=$1 AND author_id=$2 AND reviewer_id=$3", x.TeamName, x.AuthorID, x.ReviewerID)
+	if err != nil {
+		return err
+	}
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if cnt == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *PostgresRepo) ListReviewerExclusions(ctx context.Context, teamName string) ([]models.ReviewerExclusion, error) {
+	res := []models.ReviewerExclusion{}
+	if err := r.db.SelectContext(ctx, &res, "SELECT team_name, author_id, reviewer_id, created_at FROM reviewer_exclusions WHERE team_name=$1 ORDER BY author_id, reviewer_id", teamName); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ExcludedReviewersOf lists the users who must not review authorID's PRs.
+func (r *PostgresRepo) ExcludedReviewersOf(ctx context.Context, authorID string) ([]string, error) {
+	res := []string{}
+	if err := r.db.SelectContext(ctx, &res, "SELECT DISTINCT reviewer_id FROM reviewer_exclusions WHERE author_id=$1", authorID); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// RequestedReviewersOfPR lists the users the PR's create request asked for.
+func (r *PostgresRepo) RequestedReviewersOfPR(ctx context.Context, prID string) ([]string, error) {
+	res := []string{}
+	if err := r.db.SelectContext(ctx, &res, "SELECT user_id FROM pr_requested_reviewers WHERE pull_request_id=$1 ORDER BY user_id", prID); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ExcludedReviewersOfPR lists the users the PR's create request excluded.
+func (r *PostgresRepo) ExcludedReviewersOfPR(ctx context.Context, prID string) ([]string, error) {
+	res := []string{}
+	if err := r.db.SelectContext(ctx, &res, "SELECT user_id FROM pr_excluded_reviewers WHERE pull_request_id=$1 ORDER BY user_id", prID); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,65 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var ErrInvalidFallback = errors.New("invalid fallback team")
+
+// SetTeamFallbacks replaces the team's fallback list; the order of
+// fallbacks is the order they are tried in.
+func (r *PostgresRepo) SetTeamFallbacks(ctx context.Context, teamName string, fallbacks []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	var exists bool
+	if err := tx.GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM teams WHERE team_name=$1)", teamName); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if !exists {
+		tx.Rollback()
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM team_fallbacks WHERE team_name=$1", teamName); err != nil {
+		tx.Rollback()
+		return err
+	}
+	seen := map[string]bool{}
+	for i, fb := range fallbacks {
+		if fb == teamName || seen[fb] {
+			tx.Rollback()
+			return ErrInvalidFallback
+		}
+		seen[fb] = true
+		if err := tx.GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM teams WHERE team_name=$1)", fb); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if !exists {
+			tx.Rollback()
+			return ErrInvalidFallback
+		}
+		if _, err := tx.ExecContext(ctx, "INSERT INTO team_fallbacks(team_name, fallback_team, priority) VALUES($1,$2,$3)", teamName, fb, i); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func getTeamFallbacks(ctx context.Context, q sqlx.QueryerContext, teamName string) ([]string, error) {
+	res := []string{}
+	if err := sqlx.SelectContext(ctx, q, &res, "SELECT fallback_team FROM team_fallbacks WHERE team_name=$1 ORDER BY priority", teamName); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
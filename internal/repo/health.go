@@ -0,0 +1,26 @@
+package repo
+
+import (
+	"context"
+)
+
+// ExpectedSchemaVersion is the number of the newest file in migrations/.
+// Bump it together with every new migration.
+const ExpectedSchemaVersion = 22
+
+func (r *PostgresRepo) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// SchemaVersion reads the version golang-migrate recorded. dirty is set
+// when a migration failed halfway.
+func (r *PostgresRepo) SchemaVersion(ctx context.Context) (version int64, dirty bool, err error) {
+	var row struct {
+		Version int64 `db:"version"`
+		Dirty   bool  `db:"dirty"`
+	}
+	if err := r.db.GetContext(ctx, &row, "SELECT version, dirty FROM schema_migrations LIMIT 1"); err != nil {
+		return 0, false, err
+	}
+	return row.Version, row.Dirty, nil
+}
@@ -0,0 +1,26 @@
+package repo
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestExpectedSchemaVersionIsNewestMigration(t *testing.T) {
+	entries, err := os.ReadDir("../../migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newest := 0
+	for _, e := range entries {
+		n, err := strconv.Atoi(strings.SplitN(e.Name(), "_", 2)[0])
+		if err != nil {
+			t.Fatalf("migration %s has no version prefix", e.Name())
+		}
+		newest = max(newest, n)
+	}
+	if newest != ExpectedSchemaVersion {
+		t.Fatalf("newest migration is %d, ExpectedSchemaVersion is %d", newest, ExpectedSchemaVersion)
+	}
+}
@@ -0,0 +1,38 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+)
+
+func (r *PostgresRepo) SetUserLevel(ctx context.Context, userID, level string) (*models.User, error) {
+	res, err := r.db.ExecContext(ctx, "UPDATE users SET level=$1 WHERE user_id=$2", level, userID)
+	if err != nil {
+		return nil, err
+	}
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if cnt == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return r.GetUserByID(ctx, userID)
+}
+
+func (r *PostgresRepo) SetTeamLevelPolicy(ctx context.Context, teamName, level string, count int) error {
+	res, err := r.db.ExecContext(ctx, "UPDATE teams SET required_level=$1, required_level_count=$2 WHERE team_name=$3", level, count, teamName)
+	if err != nil {
+		return err
+	}
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if cnt == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+)
+
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// SetPullRequestStatus moves a PR from one status to another. It fails with
+// ErrInvalidTransition when the PR is no longer in from. Moving to OPEN
+// assigns reviewers, failing with ErrAtCapacity if one of capped filled up
+// since being picked; moving to CLOSED releases all of them. Released
+// reviewer rows are kept as assignment history.
+func (r *PostgresRepo) SetPullRequestStatus(ctx context.Context, prID, from, to string, reviewers, capped []string) (*models.PullRequest, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if to == models.StatusOpen {
+		full, err := lockAtCapacity(ctx, tx, capped)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if len(full) > 0 {
+			tx.Rollback()
+			return nil, ErrAtCapacity
+		}
+	}
+
+	closedAt := "closed_at"
+	switch to {
+	case models.StatusClosed:
+		closedAt = "now()"
+	case models.StatusOpen:
+		closedAt = "NULL"
+	}
+	res, err := tx.ExecContext(ctx, "UPDATE pull_requests SET status=$1, closed_at="+closedAt+" WHERE pull_request_id=$2 AND status=$3", to, prID, from)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if cnt == 0 {
+		tx.Rollback()
+		return nil, ErrInvalidTransition
+	}
+
+	switch to {
+	case models.StatusClosed:
+		if _, err := tx.ExecContext(ctx, "UPDATE pr_reviewers SET released_at=now() WHERE pull_request_id=$1 AND released_at IS NULL", prID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	case models.StatusOpen:
+		for _, ruid := range reviewers {
+			if err := assignReviewer(ctx, tx, prID, ruid); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+		}
+	}
+
+	pr, err := getPullRequest(ctx, tx, prID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
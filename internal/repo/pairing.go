@@ -0,0 +1,43 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+)
+
+func (r *PostgresRepo) SetTeamPairingWindow(ctx context.Context, teamName string, days int) error {
+	res, err := r.db.ExecContext(ctx, "UPDATE teams SET pairing_window_days=$1 WHERE team_name=$2", days, teamName)
+	if err != nil {
+		return err
+	}
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if cnt == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ListPairings counts reviews per author/reviewer pair assigned in the last
+// windowDays, most frequent first. An empty teamName covers all authors,
+// otherwise only authors in that team. Reviewers released by a reassignment
+// or a close keep their rows, so they still count.
+func (r *PostgresRepo) ListPairings(ctx context.Context, teamName string, windowDays int) ([]models.PairingCount, error) {
+	res := []models.PairingCount{}
+	if err := r.db.SelectContext(ctx, &res, `
+SELECT pr.author_id, rv.user_id AS reviewer_id, COUNT(1) AS reviews
+FROM pr_reviewers rv
+JOIN pull_requests pr ON pr.pull_request_id = rv.pull_request_id
+JOIN users au ON au.user_id = pr.author_id
+WHERE rv.assigned_at > now() - make_interval(days => $1)
+  AND ($2 = '' OR au.team_name = $2)
+GROUP BY pr.author_id, rv.user_id
+ORDER BY reviews DESC, pr.author_id, rv.user_id`, windowDays, teamName); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -9,7 +9,9 @@ import (
 	"time"
 
 	"github.com/Guardian1221/prsvc/internal/models"
+	"github.com/XSAM/otelsql"
 	"github.com/jmoiron/sqlx"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 
 	_ "github.com/jackc/pgx/v4/stdlib"
 )
@@ -18,17 +20,47 @@ type PostgresRepo struct {
 	db *sqlx.DB
 }
 
-func NewPostgresRepo(dsn string) (*PostgresRepo, error) {
-	db, err := sqlx.Connect("pgx", dsn)
+// PoolConfig sizes the connection pool.
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+func DefaultPoolConfig() PoolConfig {
+	return PoolConfig{
+		MaxOpenConns:    20,
+		MaxIdleConns:    5,
+		ConnMaxLifetime: 30 * time.Minute,
+	}
+}
+
+// NewPostgresRepo connects to dsn. Every statement is traced as a span of
+// the calling context.
+func NewPostgresRepo(dsn string, pool PoolConfig) (*PostgresRepo, error) {
+	sqlDB, err := otelsql.Open("pgx", dsn,
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{DisableErrSkip: true, OmitRows: true, OmitConnResetSession: true}),
+	)
 	if err != nil {
 		return nil, err
 	}
-	db.SetMaxOpenConns(20)
-	db.SetMaxIdleConns(5)
-	db.SetConnMaxLifetime(time.Minute * 30)
+	db := sqlx.NewDb(sqlDB, "pgx")
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	db.SetMaxIdleConns(pool.MaxIdleConns)
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime)
 	return &PostgresRepo{db: db}, nil
 }
 
+// DB exposes the connection pool for instrumentation.
+func (r *PostgresRepo) DB() *sql.DB {
+	return r.db.DB
+}
+
 func (r *PostgresRepo) Close() error {
 	if r.db != nil {
 		return r.db.Close()
@@ -39,6 +71,8 @@ func (r *PostgresRepo) Close() error {
 
 var ErrTeamExists = errors.New("team exists")
 
+const userColumns = "user_id, username, team_name, is_active, max_open_reviews, level, created_at, time_zone, work_start_minute, work_end_minute, work_days"
+
 func (r *PostgresRepo) CreateTeam(ctx context.Context, t models.Team) error {
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
@@ -61,7 +95,7 @@ func (r *PostgresRepo) CreateTeam(ctx context.Context, t models.Team) error {
 		return ErrTeamExists
 	}
 
-	_, err = tx.ExecContext(ctx, "INSERT INTO teams(team_name) VALUES($1)", t.TeamName)
+	_, err = tx.ExecContext(ctx, "INSERT INTO teams(team_name, min_reviewers, max_reviewers) VALUES($1,$2,$3)", t.TeamName, t.MinReviewers, t.MaxReviewers)
 	if err != nil {
 		tx.Rollback()
 		return err
@@ -86,27 +120,92 @@ ON CONFLICT (user_id) DO UPDATE SET username = EXCLUDED.username, team_name = EX
 }
 
 func (r *PostgresRepo) GetTeam(ctx context.Context, teamName string) (*models.Team, error) {
-	var t models.Team
-	t.TeamName = teamName
-	var members []models.TeamMember
+	t, err := r.GetTeamPolicy(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	members := []models.TeamMember{}
 	if err := r.db.SelectContext(ctx, &members, "SELECT user_id, username, is_active FROM users WHERE team_name=$1 ORDER BY user_id", teamName); err != nil {
-		if err == sql.ErrNoRows {
-			members = []models.TeamMember{}
-		} else {
-			return nil, err
-		}
+		return nil, err
 	}
-	var exists bool
-	if err := r.db.GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM teams WHERE team_name=$1)", teamName); err != nil {
+	t.Members = members
+	return t, nil
+}
+
+// GetTeamPolicy loads a team's settings without its members.
+func (r *PostgresRepo) GetTeamPolicy(ctx context.Context, teamName string) (*models.Team, error) {
+	var t models.Team
+	if err := r.db.GetContext(ctx, &t, "SELECT team_name, min_reviewers, max_reviewers, required_approvals, review_sla_minutes, auto_escalate, escalate_after_minutes, max_auto_reassignments, pairing_window_days, required_level, required_level_count FROM teams WHERE team_name=$1", teamName); err != nil {
 		return nil, err
 	}
-	if !exists {
-		return nil, sql.ErrNoRows
+	fallbacks, err := getTeamFallbacks(ctx, r.db, teamName)
+	if err != nil {
+		return nil, err
 	}
-	t.Members = members
+	t.FallbackTeams = fallbacks
 	return &t, nil
 }
 
+func (r *PostgresRepo) SetTeamReviewSLA(ctx context.Context, teamName string, minutes int) error {
+	res, err := r.db.ExecContext(ctx, "UPDATE teams SET review_sla_minutes=$1 WHERE team_name=$2", minutes, teamName)
+	if err != nil {
+		return err
+	}
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if cnt == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *PostgresRepo) SetTeamEscalationPolicy(ctx context.Context, teamName string, auto bool, afterMinutes, maxReassignments int) error {
+	res, err := r.db.ExecContext(ctx, "UPDATE teams SET auto_escalate=$1, escalate_after_minutes=$2, max_auto_reassignments=$3 WHERE team_name=$4", auto, afterMinutes, maxReassignments, teamName)
+	if err != nil {
+		return err
+	}
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if cnt == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *PostgresRepo) SetTeamRequiredApprovals(ctx context.Context, teamName string, required int) error {
+	res, err := r.db.ExecContext(ctx, "UPDATE teams SET required_approvals=$1 WHERE team_name=$2", required, teamName)
+	if err != nil {
+		return err
+	}
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if cnt == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *PostgresRepo) SetTeamReviewerPolicy(ctx context.Context, teamName string, minReviewers, maxReviewers int) error {
+	res, err := r.db.ExecContext(ctx, "UPDATE teams SET min_reviewers=$1, max_reviewers=$2 WHERE team_name=$3", minReviewers, maxReviewers, teamName)
+	if err != nil {
+		return err
+	}
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if cnt == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
 func (r *PostgresRepo) SetUserIsActive(ctx context.Context, userID string, active bool) (*models.User, error) {
 	res, err := r.db.ExecContext(ctx, "UPDATE users SET is_active=$1 WHERE user_id=$2", active, userID)
 	if err != nil {
@@ -120,15 +219,54 @@ func (r *PostgresRepo) SetUserIsActive(ctx context.Context, userID string, activ
 		return nil, sql.ErrNoRows
 	}
 	var u models.User
-	if err := r.db.GetContext(ctx, &u, "SELECT user_id, username, team_name, is_active, created_at FROM users WHERE user_id=$1", userID); err != nil {
+	if err := r.db.GetContext(ctx, &u, "SELECT "+userColumns+" FROM users WHERE user_id=$1", userID); err != nil {
 		return nil, err
 	}
 	return &u, nil
 }
 
+func (r *PostgresRepo) SetUserMaxOpenReviews(ctx context.Context, userID string, max *int) (*models.User, error) {
+	res, err := r.db.ExecContext(ctx, "UPDATE users SET max_open_reviews=$1 WHERE user_id=$2", max, userID)
+	if err != nil {
+		return nil, err
+	}
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if cnt == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return r.GetUserByID(ctx, userID)
+}
+
+func (r *PostgresRepo) SetUserWorkingHours(ctx context.Context, userID string, wh models.WorkingHours) (*models.User, error) {
+	res, err := r.db.ExecContext(ctx, "UPDATE users SET time_zone=$1, work_start_minute=$2, work_end_minute=$3, work_days=$4 WHERE user_id=$5",
+		wh.TimeZone, wh.WorkStartMinute, wh.WorkEndMinute, wh.WorkDays, userID)
+	if err != nil {
+		return nil, err
+	}
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if cnt == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return r.GetUserByID(ctx, userID)
+}
+
+// KnownTimeZone reports whether Postgres can convert times to the zone name,
+// which the selection query does for every candidate.
+func (r *PostgresRepo) KnownTimeZone(ctx context.Context, name string) (bool, error) {
+	var known bool
+	err := r.db.GetContext(ctx, &known, "SELECT EXISTS(SELECT 1 FROM pg_timezone_names WHERE name=$1)", name)
+	return known, err
+}
+
 func (r *PostgresRepo) GetUserByID(ctx context.Context, userID string) (*models.User, error) {
 	var u models.User
-	if err := r.db.GetContext(ctx, &u, "SELECT user_id, username, team_name, is_active, created_at FROM users WHERE user_id=$1", userID); err != nil {
+	if err := r.db.GetContext(ctx, &u, "SELECT "+userColumns+" FROM users WHERE user_id=$1", userID); err != nil {
 		return nil, err
 	}
 	return &u, nil
@@ -137,7 +275,13 @@ func (r *PostgresRepo) GetUserByID(ctx context.Context, userID string) (*models.
 
 var ErrPRExists = errors.New("pr exists")
 
-func (r *PostgresRepo) CreatePullRequestWithReviewers(ctx context.Context, pr models.PullRequest, reviewers []string) error {
+// CreatePullRequestWithReviewers stores pr with its reviewers. capped lists
+// the reviewers that were picked automatically and must stay within
+// max_open_reviews; if one of them filled up meanwhile it fails with
+// ErrAtCapacity. requested and excluded are stored so that staffing the PR
+// again, when a draft is marked ready or a closed PR reopened, asks for the
+// same reviewers, and later reassignments skip the excluded ones.
+func (r *PostgresRepo) CreatePullRequestWithReviewers(ctx context.Context, pr models.PullRequest, reviewers, capped, requested, excluded []string) error {
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return err
@@ -158,17 +302,44 @@ func (r *PostgresRepo) CreatePullRequestWithReviewers(ctx context.Context, pr mo
 		return ErrPRExists
 	}
 
+	full, err := lockAtCapacity(ctx, tx, capped)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if len(full) > 0 {
+		tx.Rollback()
+		return ErrAtCapacity
+	}
+
 	_, err = tx.ExecContext(ctx, `
 INSERT INTO pull_requests(pull_request_id, pull_request_name, author_id, status, created_at)
-VALUES ($1,$2,$3,'OPEN', now())
-`, pr.PullRequestID, pr.PullRequestName, pr.AuthorID)
+VALUES ($1,$2,$3,$4, now())
+`, pr.PullRequestID, pr.PullRequestName, pr.AuthorID, pr.Status)
 	if err != nil {
 		tx.Rollback()
 		return err
 	}
 
 	for _, ruid := range reviewers {
-		_, err = tx.ExecContext(ctx, "INSERT INTO pr_reviewers(pull_request_id, user_id) VALUES($1,$2)", pr.PullRequestID, ruid)
+		err = assignReviewer(ctx, tx, pr.PullRequestID, ruid)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	for _, uid := range requested {
+		_, err = tx.ExecContext(ctx, "INSERT INTO pr_requested_reviewers(pull_request_id, user_id) VALUES($1,$2) ON CONFLICT DO NOTHING", pr.PullRequestID, uid)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	// Unknown users are skipped; they can never be assigned anyway.
+	for _, uid := range excluded {
+		_, err = tx.ExecContext(ctx, "INSERT INTO pr_excluded_reviewers(pull_request_id, user_id) SELECT $1, user_id FROM users WHERE user_id=$2 ON CONFLICT DO NOTHING", pr.PullRequestID, uid)
 		if err != nil {
 			tx.Rollback()
 			return err
@@ -181,19 +352,101 @@ VALUES ($1,$2,$3,'OPEN', now())
 	return nil
 }
 
+// assignReviewer makes userID a current reviewer of prID. A reviewer who
+// was released from the PR before starts over: the old row is reused with a
+// fresh assigned_at, so earlier verdicts and reminders no longer count.
+func assignReviewer(ctx context.Context, tx *sqlx.Tx, prID, userID string) error {
+	_, err := tx.ExecContext(ctx, `
+INSERT INTO pr_reviewers(pull_request_id, user_id) VALUES($1,$2)
+ON CONFLICT (pull_request_id, user_id) DO UPDATE
+SET released_at=NULL, assigned_at=now(), first_response_at=NULL, reminded_at=NULL
+WHERE pr_reviewers.released_at IS NOT NULL
+`, prID, userID)
+	return err
+}
+
+// currentVerdict keeps the verdicts of pr_reviews r that a current reviewer
+// assignment rv gave since being assigned.
+const currentVerdict = "rv.released_at IS NULL AND r.updated_at >= rv.assigned_at"
+
 func (r *PostgresRepo) GetPullRequest(ctx context.Context, prID string) (*models.PullRequest, error) {
+	return getPullRequest(ctx, r.db, prID)
+}
+
+func getPullRequest(ctx context.Context, q sqlx.QueryerContext, prID string) (*models.PullRequest, error) {
 	var pr models.PullRequest
-	if err := r.db.GetContext(ctx, &pr, "SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at FROM pull_requests WHERE pull_request_id=$1", prID); err != nil {
+	if err := sqlx.GetContext(ctx, q, &pr, "SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at, closed_at FROM pull_requests WHERE pull_request_id=$1", prID); err != nil {
 		return nil, err
 	}
 	var revs []string
-	if err := r.db.SelectContext(ctx, &revs, "SELECT user_id FROM pr_reviewers WHERE pull_request_id=$1 ORDER BY user_id", prID); err != nil {
+	if err := sqlx.SelectContext(ctx, q, &revs, "SELECT user_id FROM pr_reviewers WHERE pull_request_id=$1 AND released_at IS NULL ORDER BY user_id", prID); err != nil {
 		return nil, err
 	}
 	pr.AssignedReviewers = revs
+	verdicts := []models.Verdict{}
+	if err := sqlx.SelectContext(ctx, q, &verdicts, `
+SELECT r.user_id, r.verdict, r.created_at, r.updated_at FROM pr_reviews r
+JOIN pr_reviewers rv ON rv.pull_request_id = r.pull_request_id AND rv.user_id = r.user_id
+WHERE r.pull_request_id=$1 AND `+currentVerdict+` ORDER BY r.user_id`, prID); err != nil {
+		return nil, err
+	}
+	pr.Verdicts = verdicts
 	return &pr, nil
 }
 
+var ErrNotEnoughApprovals = errors.New("not enough approvals")
+
+// MergePullRequest is idempotent: merging an already merged PR keeps the
+// original merged_at. Merging an OPEN PR needs requiredApprovals APPROVED
+// verdicts from its current reviewers. The bool reports whether this call
+// merged the PR, rather than finding it merged already.
+func (r *PostgresRepo) MergePullRequest(ctx context.Context, prID string, requiredApprovals int) (*models.PullRequest, bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var status string
+	if err := tx.GetContext(ctx, &status, "SELECT status FROM pull_requests WHERE pull_request_id=$1 FOR UPDATE", prID); err != nil {
+		tx.Rollback()
+		return nil, false, err
+	}
+	if status != "MERGED" && status != "OPEN" {
+		tx.Rollback()
+		return nil, false, ErrPRNotOpen
+	}
+	if status == "OPEN" {
+		if requiredApprovals > 0 {
+			var approvals int
+			if err := tx.GetContext(ctx, &approvals, `
+SELECT COUNT(1) FROM pr_reviews r
+JOIN pr_reviewers rv ON rv.pull_request_id = r.pull_request_id AND rv.user_id = r.user_id
+WHERE r.pull_request_id=$1 AND r.verdict='APPROVED' AND `+currentVerdict, prID); err != nil {
+				tx.Rollback()
+				return nil, false, err
+			}
+			if approvals < requiredApprovals {
+				tx.Rollback()
+				return nil, false, ErrNotEnoughApprovals
+			}
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE pull_requests SET status='MERGED', merged_at=now() WHERE pull_request_id=$1", prID); err != nil {
+			tx.Rollback()
+			return nil, false, err
+		}
+	}
+
+	pr, err := getPullRequest(ctx, tx, prID)
+	if err != nil {
+		tx.Rollback()
+		return nil, false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, false, err
+	}
+	return pr, status == "OPEN", nil
+}
+
 func (r *PostgresRepo) SelectRandomActiveTeamMembersExcluding(ctx context.Context, teamName string, exclude []string, limit int) ([]string, error) {
 	var args []interface{}
 	args = append(args, teamName)
@@ -221,13 +474,17 @@ func (r *PostgresRepo) SelectRandomActiveTeamMembersExcluding(ctx context.Contex
 }
 
 var ErrPRMerged = errors.New("pr merged")
+var ErrPRNotOpen = errors.New("pr not open")
 var ErrNotAssigned = errors.New("not assigned")
 var ErrNoCandidate = errors.New("no candidate")
 
-func (r *PostgresRepo) ReassignReviewer(ctx context.Context, prID string, oldReviewerID string) (string, *models.PullRequest, error) {
+// ReassignReviewer replaces oldReviewerID and records the replacement with
+// reason in pr_reassignments. Users excluded when the PR was created are
+// never picked.
+func (r *PostgresRepo) ReassignReviewer(ctx context.Context, prID string, oldReviewerID string, reason string) (*models.Reassignment, error) {
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
-		return "", nil, err
+		return nil, err
 	}
 	defer func() {
 		if err != nil {
@@ -244,105 +501,144 @@ func (r *PostgresRepo) ReassignReviewer(ctx context.Context, prID string, oldRev
 	if err := tx.GetContext(ctx, &prRow, "SELECT pull_request_id, author_id, status, merged_at FROM pull_requests WHERE pull_request_id=$1 FOR UPDATE", prID); err != nil {
 		if err == sql.ErrNoRows {
 			tx.Rollback()
-			return "", nil, sql.ErrNoRows
+			return nil, sql.ErrNoRows
 		}
 		tx.Rollback()
-		return "", nil, err
+		return nil, err
 	}
 
 	if prRow.Status == "MERGED" {
 		tx.Rollback()
-		return "", nil, ErrPRMerged
+		return nil, ErrPRMerged
+	}
+	if prRow.Status != "OPEN" {
+		tx.Rollback()
+		return nil, ErrPRNotOpen
 	}
 
 	var count int
-	if err := tx.GetContext(ctx, &count, "SELECT COUNT(1) FROM pr_reviewers WHERE pull_request_id=$1 AND user_id=$2", prID, oldReviewerID); err != nil {
+	if err := tx.GetContext(ctx, &count, "SELECT COUNT(1) FROM pr_reviewers WHERE pull_request_id=$1 AND user_id=$2 AND released_at IS NULL", prID, oldReviewerID); err != nil {
 		tx.Rollback()
-		return "", nil, err
+		return nil, err
 	}
 	if count == 0 {
 		tx.Rollback()
-		return "", nil, ErrNotAssigned
+		return nil, ErrNotAssigned
 	}
 
-	var teamName string
-	if err := tx.GetContext(ctx, &teamName, "SELECT team_name FROM users WHERE user_id=$1", oldReviewerID); err != nil {
-		if err == sql.ErrNoRows {
-			tx.Rollback()
-			return "", nil, sql.ErrNoRows
-		}
+	var currentReviewers []string
+	if err := tx.SelectContext(ctx, &currentReviewers, "SELECT user_id FROM pr_reviewers WHERE pull_request_id=$1 AND released_at IS NULL", prID); err != nil {
 		tx.Rollback()
-		return "", nil, err
+		return nil, err
 	}
-
-	var currentReviewers []string
-	if err := tx.SelectContext(ctx, &currentReviewers, "SELECT user_id FROM pr_reviewers WHERE pull_request_id=$1", prID); err != nil {
+	var excluded []string
+	if err := tx.SelectContext(ctx, &excluded, "SELECT user_id FROM pr_excluded_reviewers WHERE pull_request_id=$1", prID); err != nil {
 		tx.Rollback()
-		return "", nil, err
+		return nil, err
 	}
 	exclude := append(currentReviewers, prRow.AuthorID)
+	exclude = append(exclude, excluded...)
 
-	args := []interface{}{teamName}
-	sb := strings.Builder{}
-	sb.WriteString("SELECT user_id FROM users WHERE team_name=$1 AND is_active = true")
-	if len(exclude) > 0 {
-		sb.WriteString(" AND user_id NOT IN (")
-		for i := range exclude {
-			if i > 0 {
-				sb.WriteString(",")
-			}
-			sb.WriteString(fmt.Sprintf("$%d", i+2))
-			args = append(args, exclude[i])
+	// The replacement comes from the author's team and its fallbacks, like
+	// the reviewers picked at creation.
+	var policy struct {
+		TeamName           string `db:"team_name"`
+		PairingWindowDays  int    `db:"pairing_window_days"`
+		RequiredLevel      string `db:"required_level"`
+		RequiredLevelCount int    `db:"required_level_count"`
+	}
+	if err := tx.GetContext(ctx, &policy, "SELECT t.team_name, t.pairing_window_days, t.required_level, t.required_level_count FROM users u JOIN teams t ON t.team_name = u.team_name WHERE u.user_id=$1", prRow.AuthorID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	teamName := policy.TeamName
+	base := CandidateFilter{Exclude: exclude, Author: prRow.AuthorID, PairingWindowDays: policy.PairingWindowDays}
+
+	// A reviewer who counts towards the level requirement is replaced by
+	// someone at that level whenever possible.
+	var remaining []string
+	for _, id := range currentReviewers {
+		if id != oldReviewerID {
+			remaining = append(remaining, id)
 		}
-		sb.WriteString(")")
 	}
-	sb.WriteString(" ORDER BY RANDOM() LIMIT 1")
-	var candidate string
-	row := tx.QueryRowxContext(ctx, sb.String(), args...)
-	if err := row.Scan(&candidate); err != nil {
-		if err == sql.ErrNoRows {
+	atLevel := 0
+	if policy.RequiredLevelCount > 0 {
+		if atLevel, err = countAtLevel(ctx, tx, remaining, policy.RequiredLevel); err != nil {
 			tx.Rollback()
-			return "", nil, ErrNoCandidate
+			return nil, err
+		}
+	}
+	levelUnmet := false
+	var candidate string
+	var fromFallback bool
+	if atLevel < policy.RequiredLevelCount {
+		leveled := base
+		leveled.MinLevel = policy.RequiredLevel
+		candidate, fromFallback, err = r.pickReplacement(ctx, tx, teamName, leveled)
+		if err == ErrNoCandidate {
+			levelUnmet = true
+			candidate, fromFallback, err = r.pickReplacement(ctx, tx, teamName, base)
 		}
+	} else {
+		candidate, fromFallback, err = r.pickReplacement(ctx, tx, teamName, base)
+	}
+	if err != nil {
 		tx.Rollback()
-		return "", nil, err
+		return nil, err
 	}
-
-	if _, err := tx.ExecContext(ctx, "DELETE FROM pr_reviewers WHERE pull_request_id=$1 AND user_id=$2", prID, oldReviewerID); err != nil {
+	full, err := lockAtCapacity(ctx, tx, []string{candidate})
+	if err != nil {
 		tx.Rollback()
-		return "", nil, err
+		return nil, err
 	}
-	if _, err := tx.ExecContext(ctx, "INSERT INTO pr_reviewers(pull_request_id, user_id) VALUES($1,$2)", prID, candidate); err != nil {
+	if len(full) > 0 {
 		tx.Rollback()
-		return "", nil, err
+		return nil, ErrAtCapacity
 	}
 
-	var updated models.PullRequest
-	if err := tx.GetContext(ctx, &updated, "SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at FROM pull_requests WHERE pull_request_id=$1", prID); err != nil {
+	if _, err := tx.ExecContext(ctx, "UPDATE pr_reviewers SET released_at=now() WHERE pull_request_id=$1 AND user_id=$2", prID, oldReviewerID); err != nil {
 		tx.Rollback()
-		return "", nil, err
+		return nil, err
 	}
-	var revs []string
-	if err := tx.SelectContext(ctx, &revs, "SELECT user_id FROM pr_reviewers WHERE pull_request_id=$1 ORDER BY user_id", prID); err != nil {
+	if err := assignReviewer(ctx, tx, prID, candidate); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO pr_reassignments(pull_request_id, old_user_id, new_user_id, reason) VALUES($1,$2,$3,$4)", prID, oldReviewerID, candidate, reason); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	updated, err := getPullRequest(ctx, tx, prID)
+	if err != nil {
 		tx.Rollback()
-		return "", nil, err
+		return nil, err
 	}
-	updated.AssignedReviewers = revs
 
 	if err := tx.Commit(); err != nil {
-		return "", nil, err
+		return nil, err
 	}
-	return candidate, &updated, nil
+	return &models.Reassignment{PR: updated, ReplacedBy: candidate, FromFallback: fromFallback, LevelUnmet: levelUnmet}, nil
 }
 
-func (r *PostgresRepo) SelectInitialReviewers(ctx context.Context, authorID string, limit int) ([]string, error) {
-	var team string
-	if err := r.db.GetContext(ctx, &team, "SELECT team_name FROM users WHERE user_id=$1", authorID); err != nil {
-		return nil, err
+// pickReplacement looks for one reviewer matching f in teamName first and
+// then in its fallback teams, in priority order.
+func (r *PostgresRepo) pickReplacement(ctx context.Context, tx *sqlx.Tx, teamName string, f CandidateFilter) (string, bool, error) {
+	fallbacks, err := getTeamFallbacks(ctx, tx, teamName)
+	if err != nil {
+		return "", false, err
 	}
-	var res []string
-	if err := r.db.SelectContext(ctx, &res, "SELECT user_id FROM users WHERE team_name=$1 AND is_active = true AND user_id <> $2 ORDER BY RANDOM() LIMIT $3", team, authorID, limit); err != nil {
-		return nil, err
+	for i, team := range append([]string{teamName}, fallbacks...) {
+		f.Teams = []string{team}
+		f.Limit = 1
+		picked, err := selectCandidates(ctx, tx, f)
+		if err != nil {
+			return "", false, err
+		}
+		if len(picked) > 0 {
+			return picked[0], i > 0, nil
+		}
 	}
-	return res, nil
+	return "", false, ErrNoCandidate
 }
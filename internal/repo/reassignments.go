@@ -0,0 +1,23 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+)
+
+func (r *PostgresRepo) ListReassignments(ctx context.Context, prID string) ([]models.ReassignmentRecord, error) {
+	res := []models.ReassignmentRecord{}
+	if err := r.db.SelectContext(ctx, &res, "SELECT old_user_id, new_user_id, reason, created_at FROM pr_reassignments WHERE pull_request_id=$1 ORDER BY id", prID); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (r *PostgresRepo) CountReassignments(ctx context.Context, prID, reason string) (int, error) {
+	var n int
+	if err := r.db.GetContext(ctx, &n, "SELECT COUNT(1) FROM pr_reassignments WHERE pull_request_id=$1 AND reason=$2", prID, reason); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
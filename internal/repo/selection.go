@@ -0,0 +1,169 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// CandidateFilter describes which active users may be picked as reviewers.
+// Teams and Users are alternatives: a user qualifies by being in one of the
+// teams or by being listed explicitly. Users inside an absence window never
+// qualify, and users at max_open_reviews qualify only with IgnoreCapacity.
+// With Author and PairingWindowDays set, users who reviewed Author less often
+// in that window come first, and users excluded from reviewing Author never
+// qualify. MinLevel, when set, skips users below it.
+type CandidateFilter struct {
+	Teams   []string
+	Users   []string
+	Exclude []string
+	Limit   int
+
+	IgnoreCapacity bool
+
+	Author            string
+	PairingWindowDays int
+
+	MinLevel string
+}
+
+func (r *PostgresRepo) SelectCandidates(ctx context.Context, f CandidateFilter) ([]string, error) {
+	return selectCandidates(ctx, r.db, f)
+}
+
+func selectCandidates(ctx context.Context, q sqlx.QueryerContext, f CandidateFilter) ([]string, error) {
+	if f.Limit <= 0 || (len(f.Teams) == 0 && len(f.Users) == 0) {
+		return []string{}, nil
+	}
+	query, args := f.build()
+	res := []string{}
+	if err := sqlx.SelectContext(ctx, q, &res, query, args...); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// inWorkingHours is true for users whose local time is inside their working
+// hours, so they are preferred over those who are off.
+const inWorkingHours = `(
+((u.work_days >> EXTRACT(DOW FROM now() AT TIME ZONE u.time_zone)::int) & 1) = 1
+AND (EXTRACT(HOUR FROM now() AT TIME ZONE u.time_zone) * 60 + EXTRACT(MINUTE FROM now() AT TIME ZONE u.time_zone))
+    BETWEEN u.work_start_minute AND u.work_end_minute - 1)`
+
+func (f CandidateFilter) build() (string, []interface{}) {
+	var args []interface{}
+	list := func(vals []string) string {
+		ph := make([]string, len(vals))
+		for i, v := range vals {
+			args = append(args, v)
+			ph[i] = fmt.Sprintf("$%d", len(args))
+		}
+		return strings.Join(ph, ",")
+	}
+
+	conds := []string{
+		"u.is_active = true",
+		"NOT EXISTS (SELECT 1 FROM user_absences a WHERE a.user_id = u.user_id AND a.starts_at <= now() AND a.ends_at > now())",
+	}
+	var scope []string
+	if len(f.Teams) > 0 {
+		scope = append(scope, "u.team_name IN ("+list(f.Teams)+")")
+	}
+	if len(f.Users) > 0 {
+		scope = append(scope, "u.user_id IN ("+list(f.Users)+")")
+	}
+	conds = append(conds, "("+strings.Join(scope, " OR ")+")")
+	if !f.IgnoreCapacity {
+		conds = append(conds, `(u.max_open_reviews IS NULL OR (
+SELECT COUNT(1) FROM pr_reviewers rv JOIN pull_requests p ON p.pull_request_id = rv.pull_request_id
+WHERE rv.user_id = u.user_id AND rv.released_at IS NULL AND p.status = 'OPEN') < u.max_open_reviews)`)
+	}
+	if len(f.Exclude) > 0 {
+		conds = append(conds, "u.user_id NOT IN ("+list(f.Exclude)+")")
+	}
+	author := ""
+	if f.Author != "" {
+		author = list([]string{f.Author})
+		conds = append(conds, "NOT EXISTS (SELECT 1 FROM reviewer_exclusions x WHERE x.author_id = "+author+" AND x.reviewer_id = u.user_id)")
+	}
+	if f.MinLevel != "" {
+		conds = append(conds, "u.level >= "+list([]string{f.MinLevel})+"::user_level")
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString("SELECT u.user_id FROM users u WHERE ")
+	sb.WriteString(strings.Join(conds, " AND "))
+	sb.WriteString(" ORDER BY ")
+	sb.WriteString(inWorkingHours)
+	sb.WriteString(" DESC, ")
+	if f.Author != "" && f.PairingWindowDays > 0 {
+		args = append(args, f.PairingWindowDays)
+		sb.WriteString(fmt.Sprintf(`(SELECT COUNT(1) FROM pr_reviewers rv JOIN pull_requests p ON p.pull_request_id = rv.pull_request_id
+WHERE rv.user_id = u.user_id AND p.author_id = %s AND rv.assigned_at > now() - make_interval(days => $%d)), `, author, len(args)))
+	}
+	sb.WriteString("RANDOM() LIMIT ")
+	sb.WriteString(fmt.Sprintf("%d", f.Limit))
+	return sb.String(), args
+}
+
+// ErrAtCapacity means a picked reviewer reached max_open_reviews through a
+// concurrent assignment after being selected; selecting again skips them.
+var ErrAtCapacity = errors.New("reviewer at capacity")
+
+// lockAtCapacity locks the users rows of userIDs and returns those already
+// at max_open_reviews. Every assignment that respects the cap takes these
+// locks first, in user_id order so concurrent ones cannot deadlock, so the
+// caller can assign the others without a concurrent create or reassignment
+// pushing them over their cap before it commits.
+func lockAtCapacity(ctx context.Context, tx *sqlx.Tx, userIDs []string) ([]string, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	args := make([]interface{}, len(userIDs))
+	ph := make([]string, len(userIDs))
+	for i, id := range userIDs {
+		args[i] = id
+		ph[i] = fmt.Sprintf("$%d", i+1)
+	}
+	in := strings.Join(ph, ",")
+	var locked []string
+	if err := tx.SelectContext(ctx, &locked, "SELECT user_id FROM users WHERE user_id IN ("+in+") ORDER BY user_id FOR UPDATE", args...); err != nil {
+		return nil, err
+	}
+	full := []string{}
+	if err := tx.SelectContext(ctx, &full, `
+SELECT u.user_id FROM users u
+WHERE u.user_id IN (`+in+`) AND u.max_open_reviews IS NOT NULL AND (
+SELECT COUNT(1) FROM pr_reviewers rv JOIN pull_requests p ON p.pull_request_id = rv.pull_request_id
+WHERE rv.user_id = u.user_id AND rv.released_at IS NULL AND p.status = 'OPEN') >= u.max_open_reviews
+`, args...); err != nil {
+		return nil, err
+	}
+	return full, nil
+}
+
+func (r *PostgresRepo) CountAtLevel(ctx context.Context, userIDs []string, level string) (int, error) {
+	return countAtLevel(ctx, r.db, userIDs, level)
+}
+
+// countAtLevel counts how many of userIDs are at level or above.
+func countAtLevel(ctx context.Context, q sqlx.QueryerContext, userIDs []string, level string) (int, error) {
+	if len(userIDs) == 0 {
+		return 0, nil
+	}
+	args := []interface{}{level}
+	ph := make([]string, len(userIDs))
+	for i, id := range userIDs {
+		args = append(args, id)
+		ph[i] = fmt.Sprintf("$%d", len(args))
+	}
+	query := "SELECT COUNT(1) FROM users WHERE level >= $1::user_level AND user_id IN (" + strings.Join(ph, ",") + ")"
+	var n int
+	if err := sqlx.GetContext(ctx, q, &n, query, args...); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
@@ -0,0 +1,40 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+)
+
+// ListPendingReviews returns unanswered assignments on OPEN PRs, optionally
+// limited to PRs whose author is in teamName.
+func (r *PostgresRepo) ListPendingReviews(ctx context.Context, teamName string) ([]models.PendingReview, error) {
+	query := `
+SELECT rv.pull_request_id, pr.pull_request_name, pr.author_id, rv.user_id, au.team_name,
+       rv.assigned_at, rv.reminded_at, t.review_sla_minutes,
+       t.auto_escalate, t.escalate_after_minutes, t.max_auto_reassignments,
+       ru.time_zone, ru.work_start_minute, ru.work_end_minute, ru.work_days
+FROM pr_reviewers rv
+JOIN pull_requests pr ON pr.pull_request_id = rv.pull_request_id
+JOIN users ru ON ru.user_id = rv.user_id
+JOIN users au ON au.user_id = pr.author_id
+JOIN teams t ON t.team_name = au.team_name
+WHERE pr.status = 'OPEN' AND rv.released_at IS NULL AND rv.first_response_at IS NULL`
+	var args []interface{}
+	if teamName != "" {
+		query += " AND au.team_name = $1"
+		args = append(args, teamName)
+	}
+	query += " ORDER BY rv.assigned_at"
+
+	res := []models.PendingReview{}
+	if err := r.db.SelectContext(ctx, &res, query, args...); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (r *PostgresRepo) MarkReminded(ctx context.Context, prID, userID string) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE pr_reviewers SET reminded_at=now() WHERE pull_request_id=$1 AND user_id=$2 AND released_at IS NULL", prID, userID)
+	return err
+}
@@ -0,0 +1,48 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+)
+
+const apiTokenColumns = "id, name, scope, user_id, created_at, revoked_at"
+
+func (r *PostgresRepo) CreateAPIToken(ctx context.Context, t models.APIToken, hash string) (*models.APIToken, error) {
+	var created models.APIToken
+	if err := r.db.GetContext(ctx, &created, "INSERT INTO api_tokens(name, token_hash, scope, user_id) VALUES($1,$2,$3,$4) RETURNING "+apiTokenColumns,
+		t.Name, hash, t.Scope, t.UserID); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// GetActiveAPIToken finds the unrevoked token with the given hash.
+func (r *PostgresRepo) GetActiveAPIToken(ctx context.Context, hash string) (*models.APIToken, error) {
+	var t models.APIToken
+	if err := r.db.GetContext(ctx, &t, "SELECT "+apiTokenColumns+" FROM api_tokens WHERE token_hash=$1 AND revoked_at IS NULL", hash); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *PostgresRepo) ListAPITokens(ctx context.Context) ([]models.APIToken, error) {
+	res := []models.APIToken{}
+	if err := r.db.SelectContext(ctx, &res, "SELECT "+apiTokenColumns+" FROM api_tokens ORDER BY id"); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (r *PostgresRepo) RevokeAPIToken(ctx context.Context, id int64) (*models.APIToken, error) {
+	var t models.APIToken
+	err := r.db.GetContext(ctx, &t, "UPDATE api_tokens SET revoked_at=COALESCE(revoked_at, now()) WHERE id=$1 RETURNING "+apiTokenColumns, id)
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,92 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+)
+
+func (r *PostgresRepo) SetExternalUser(ctx context.Context, provider, externalID, userID string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	var exists bool
+	if err := tx.GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM users WHERE user_id=$1)", userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if !exists {
+		tx.Rollback()
+		return sql.ErrNoRows
+	}
+
+	// A prsvc user has at most one identity per provider, so drop any
+	// previous mapping before taking over the external ID.
+	if _, err := tx.ExecContext(ctx, "DELETE FROM vcs_user_mappings WHERE provider=$1 AND user_id=$2", provider, userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+INSERT INTO vcs_user_mappings(provider, external_id, user_id)
+VALUES ($1,$2,$3)
+ON CONFLICT (provider, external_id) DO UPDATE SET user_id = EXCLUDED.user_id
+`, provider, externalID, userID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *PostgresRepo) GetUserIDByExternal(ctx context.Context, provider, externalID string) (string, error) {
+	var userID string
+	if err := r.db.GetContext(ctx, &userID, "SELECT user_id FROM vcs_user_mappings WHERE provider=$1 AND external_id=$2", provider, externalID); err != nil {
+		return "", err
+	}
+	return userID, nil
+}
+
+// ClaimDelivery records a webhook delivery and reports whether this call
+// inserted it. Concurrent redeliveries race on the primary key, so exactly
+// one of them gets true.
+func (r *PostgresRepo) ClaimDelivery(ctx context.Context, provider, deliveryID string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, "INSERT INTO vcs_deliveries(provider, delivery_id) VALUES($1,$2) ON CONFLICT DO NOTHING", provider, deliveryID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// ReleaseDelivery drops a claim whose delivery failed, so the host's retry
+// is applied instead of being taken for a duplicate.
+func (r *PostgresRepo) ReleaseDelivery(ctx context.Context, provider, deliveryID string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM vcs_deliveries WHERE provider=$1 AND delivery_id=$2", provider, deliveryID)
+	return err
+}
+
+func (r *PostgresRepo) GetExternalIDByUser(ctx context.Context, provider, userID string) (string, error) {
+	var externalID string
+	if err := r.db.GetContext(ctx, &externalID, "SELECT external_id FROM vcs_user_mappings WHERE provider=$1 AND user_id=$2", provider, userID); err != nil {
+		return "", err
+	}
+	return externalID, nil
+}
+
+func (r *PostgresRepo) SaveDeadLetter(ctx context.Context, provider, prID string, reviewers []string, attempts int, lastErr string) error {
+	revs, err := json.Marshal(reviewers)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `
+INSERT INTO vcs_dead_letters(provider, pull_request_id, reviewers, attempts, last_error)
+VALUES ($1,$2,$3::jsonb,$4,$5)
+`, provider, prID, string(revs), attempts, lastErr)
+	return err
+}
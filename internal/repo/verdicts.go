@@ -0,0 +1,63 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+)
+
+// SubmitVerdict records the reviewer's verdict, replacing any earlier one.
+func (r *PostgresRepo) SubmitVerdict(ctx context.Context, prID, userID, verdict string) (*models.PullRequest, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var status string
+	if err := tx.GetContext(ctx, &status, "SELECT status FROM pull_requests WHERE pull_request_id=$1 FOR UPDATE", prID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if status == "MERGED" {
+		tx.Rollback()
+		return nil, ErrPRMerged
+	}
+	if status != "OPEN" {
+		tx.Rollback()
+		return nil, ErrPRNotOpen
+	}
+
+	var assigned bool
+	if err := tx.GetContext(ctx, &assigned, "SELECT EXISTS(SELECT 1 FROM pr_reviewers WHERE pull_request_id=$1 AND user_id=$2 AND released_at IS NULL)", prID, userID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if !assigned {
+		tx.Rollback()
+		return nil, ErrNotAssigned
+	}
+
+	_, err = tx.ExecContext(ctx, `
+INSERT INTO pr_reviews(pull_request_id, user_id, verdict, created_at, updated_at)
+VALUES ($1,$2,$3, now(), now())
+ON CONFLICT (pull_request_id, user_id) DO UPDATE SET verdict = EXCLUDED.verdict, updated_at = EXCLUDED.updated_at
+`, prID, userID, verdict)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE pr_reviewers SET first_response_at=COALESCE(first_response_at, now()) WHERE pull_request_id=$1 AND user_id=$2 AND released_at IS NULL", prID, userID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	pr, err := getPullRequest(ctx, tx, prID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
@@ -0,0 +1,54 @@
+// Package scheduler runs the service's periodic background jobs inside the
+// prsvc process.
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type job struct {
+	name     string
+	interval time.Duration
+	run      func(ctx context.Context) error
+}
+
+type Scheduler struct {
+	jobs []job
+}
+
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// Add registers fn to run every interval. Jobs must be added before Run.
+func (s *Scheduler) Add(name string, interval time.Duration, fn func(ctx context.Context) error) {
+	s.jobs = append(s.jobs, job{name: name, interval: interval, run: fn})
+}
+
+// Run starts every job and blocks until ctx is cancelled and all running
+// jobs have returned. A failing run is logged and retried on the next tick.
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, j := range s.jobs {
+		wg.Add(1)
+		go func(j job) {
+			defer wg.Done()
+			ticker := time.NewTicker(j.interval)
+			defer ticker.Stop()
+			for {
+				if err := j.run(ctx); err != nil && ctx.Err() == nil {
+					slog.ErrorContext(ctx, "scheduled job failed", "job", j.name, "err", err)
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}(j)
+	}
+	wg.Wait()
+}
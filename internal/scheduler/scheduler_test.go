@@ -0,0 +1,41 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSchedulerRunsJobsUntilCancelled(t *testing.T) {
+	var ok, failing atomic.Int32
+	s := New()
+	s.Add("ok", time.Millisecond, func(context.Context) error {
+		ok.Add(1)
+		return nil
+	})
+	s.Add("failing", time.Millisecond, func(context.Context) error {
+		failing.Add(1)
+		return errors.New("boom")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+
+	if ok.Load() < 2 || failing.Load() < 2 {
+		t.Fatalf("jobs should keep running after errors: ok=%d failing=%d", ok.Load(), failing.Load())
+	}
+}
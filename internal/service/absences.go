@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+	"github.com/Guardian1221/prsvc/internal/repo"
+)
+
+var ErrInvalidAbsence = errors.New("invalid absence window")
+
+func (s *Service) AddAbsence(ctx context.Context, a models.Absence) (*models.Absence, error) {
+	ctx, span := tracer.Start(ctx, "Service.AddAbsence")
+	defer span.End()
+
+	if a.StartsAt.IsZero() || !a.EndsAt.After(a.StartsAt) {
+		return nil, ErrInvalidAbsence
+	}
+	if _, err := s.repo.GetUserByID(ctx, a.UserID); err != nil {
+		return nil, err
+	}
+	return s.repo.AddAbsence(ctx, a)
+}
+
+func (s *Service) ListAbsences(ctx context.Context, userID string) ([]models.Absence, error) {
+	ctx, span := tracer.Start(ctx, "Service.ListAbsences")
+	defer span.End()
+
+	return s.repo.ListAbsences(ctx, userID)
+}
+
+func (s *Service) DeleteAbsence(ctx context.Context, id int64) error {
+	ctx, span := tracer.Start(ctx, "Service.DeleteAbsence")
+	defer span.End()
+
+	return s.repo.DeleteAbsence(ctx, id)
+}
+
+// ReassignAbsentReviewers moves the OPEN reviews of users whose absence just
+// started and asked for it. A review without a replacement stays with the
+// absent user; the absence is still marked handled so it is not retried
+// every tick. An unexpected error on one PR does not hold up the others: it
+// is logged, the absence is left for the next tick, and the errors are
+// returned together.
+func (s *Service) ReassignAbsentReviewers(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "Service.ReassignAbsentReviewers")
+	defer span.End()
+
+	absences, err := s.repo.ListStartedAutoReassignAbsences(ctx)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, a := range absences {
+		prIDs, err := s.repo.ListOpenReviewsOf(ctx, a.UserID)
+		if err != nil {
+			slog.ErrorContext(ctx, "absence list reviews failed", "absence_id", a.ID, "reviewer", a.UserID, "err", err)
+			errs = append(errs, err)
+			continue
+		}
+		failed := false
+		for _, prID := range prIDs {
+			if _, err := s.ReassignReviewer(ctx, prID, a.UserID, models.ReasonAbsence); err != nil {
+				if err == repo.ErrNoCandidate || err == repo.ErrPRMerged || err == repo.ErrPRNotOpen || err == repo.ErrNotAssigned {
+					slog.WarnContext(ctx, "absence cannot reassign", "absence_id", a.ID, "pull_request_id", prID, "reviewer", a.UserID, "err", err)
+					continue
+				}
+				slog.ErrorContext(ctx, "absence reassign failed", "absence_id", a.ID, "pull_request_id", prID, "reviewer", a.UserID, "err", err)
+				errs = append(errs, err)
+				failed = true
+			}
+		}
+		if failed {
+			continue
+		}
+		if err := s.repo.MarkAbsenceReassigned(ctx, a.ID); err != nil {
+			slog.ErrorContext(ctx, "absence mark reassigned failed", "absence_id", a.ID, "err", err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Guardian1221/prsvc/internal/auth"
+)
+
+var ErrForbidden = errors.New("forbidden")
+
+// AuthorizePRAuthor lets the caller act on prID only if they are its author
+// or an admin. Requests without a caller, i.e. with authentication turned
+// off, are let through.
+func (s *Service) AuthorizePRAuthor(ctx context.Context, prID string) error {
+	ctx, span := tracer.Start(ctx, "Service.AuthorizePRAuthor")
+	defer span.End()
+
+	p := auth.FromContext(ctx)
+	if p == nil || p.Scope == auth.ScopeAdmin {
+		return nil
+	}
+	pr, err := s.repo.GetPullRequest(ctx, prID)
+	if err != nil {
+		return err
+	}
+	if p.UserID == "" || p.UserID != pr.AuthorID {
+		return ErrForbidden
+	}
+	return nil
+}
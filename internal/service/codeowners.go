@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/Guardian1221/prsvc/internal/codeowners"
+)
+
+var ErrInvalidCodeOwners = errors.New("invalid CODEOWNERS")
+
+func (s *Service) SetCodeOwners(ctx context.Context, repository, content string) error {
+	ctx, span := tracer.Start(ctx, "Service.SetCodeOwners")
+	defer span.End()
+
+	if _, err := codeowners.Parse(content); err != nil {
+		return ErrInvalidCodeOwners
+	}
+	return s.repo.SetCodeOwners(ctx, repository, content)
+}
+
+func (s *Service) GetCodeOwners(ctx context.Context, repository string) (string, error) {
+	ctx, span := tracer.Start(ctx, "Service.GetCodeOwners")
+	defer span.End()
+
+	return s.repo.GetCodeOwners(ctx, repository)
+}
+
+// codeOwnersOf returns the user IDs and team names owning paths. A
+// repository without a registered CODEOWNERS has no owners, so selection
+// falls back to the author's team.
+func (s *Service) codeOwnersOf(ctx context.Context, repository string, paths []string) ([]string, error) {
+	content, err := s.repo.GetCodeOwners(ctx, repository)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	f, err := codeowners.Parse(content)
+	if err != nil {
+		return nil, ErrInvalidCodeOwners
+	}
+	return f.OwnerNames(paths), nil
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+	"github.com/Guardian1221/prsvc/internal/repo"
+)
+
+var ErrInvalidEscalationPolicy = errors.New("invalid escalation policy")
+
+func (s *Service) SetTeamEscalationPolicy(ctx context.Context, teamName string, auto bool, afterMinutes, maxReassignments int) (*models.Team, error) {
+	ctx, span := tracer.Start(ctx, "Service.SetTeamEscalationPolicy")
+	defer span.End()
+
+	if afterMinutes <= 0 || maxReassignments < 0 {
+		return nil, ErrInvalidEscalationPolicy
+	}
+	if err := s.repo.SetTeamEscalationPolicy(ctx, teamName, auto, afterMinutes, maxReassignments); err != nil {
+		return nil, err
+	}
+	return s.repo.GetTeam(ctx, teamName)
+}
+
+// EscalateStaleReviews reassigns reviews that have been idle longer than
+// their team's escalate_after_minutes of the reviewer's working time, for
+// teams that opted in. Each PR is escalated at most max_auto_reassignments
+// times. A failing review does not stop the others; all failures are
+// returned joined. It is run periodically by the scheduler.
+func (s *Service) EscalateStaleReviews(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "Service.EscalateStaleReviews")
+	defer span.End()
+
+	pending, err := s.repo.ListPendingReviews(ctx, "")
+	if err != nil {
+		return err
+	}
+	now := s.now()
+	var errs []error
+	for _, p := range pending {
+		if !p.AutoEscalate || workingSince(reviewerCalendar(p), p.AssignedAt, now) < time.Duration(p.EscalateAfterMinutes)*time.Minute {
+			continue
+		}
+		done, err := s.repo.CountReassignments(ctx, p.PullRequestID, models.ReasonEscalation)
+		if err != nil {
+			slog.ErrorContext(ctx, "escalation count reassignments failed", "pull_request_id", p.PullRequestID, "err", err)
+			errs = append(errs, err)
+			continue
+		}
+		if done >= p.MaxAutoReassignments {
+			continue
+		}
+		res, err := s.ReassignReviewer(ctx, p.PullRequestID, p.ReviewerID, models.ReasonEscalation)
+		if err != nil {
+			if err == repo.ErrNoCandidate || err == repo.ErrPRMerged || err == repo.ErrPRNotOpen || err == repo.ErrNotAssigned {
+				slog.WarnContext(ctx, "escalation cannot reassign", "pull_request_id", p.PullRequestID, "reviewer", p.ReviewerID, "err", err)
+				continue
+			}
+			slog.ErrorContext(ctx, "escalation reassign failed", "pull_request_id", p.PullRequestID, "reviewer", p.ReviewerID, "err", err)
+			errs = append(errs, err)
+			continue
+		}
+		slog.InfoContext(ctx, "escalation reassigned idle review", "pull_request_id", p.PullRequestID, "reviewer", p.ReviewerID, "replaced_by", res.ReplacedBy)
+	}
+	return errors.Join(errs...)
+}
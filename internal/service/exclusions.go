@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+	"github.com/Guardian1221/prsvc/internal/repo"
+)
+
+var (
+	ErrInvalidExclusion         = repo.ErrInvalidExclusion
+	ErrInvalidRequestedReviewer = errors.New("invalid requested reviewer")
+)
+
+func (s *Service) AddReviewerExclusion(ctx context.Context, x models.ReviewerExclusion) ([]models.ReviewerExclusion, error) {
+	ctx, span := tracer.Start(ctx, "Service.AddReviewerExclusion")
+	defer span.End()
+
+	if err := s.repo.AddReviewerExclusion(ctx, x); err != nil {
+		return nil, err
+	}
+	return s.repo.ListReviewerExclusions(ctx, x.TeamName)
+}
+
+func (s *Service) DeleteReviewerExclusion(ctx context.Context, x models.ReviewerExclusion) ([]models.ReviewerExclusion, error) {
+	ctx, span := tracer.Start(ctx, "Service.DeleteReviewerExclusion")
+	defer span.End()
+
+	if err := s.repo.DeleteReviewerExclusion(ctx, x); err != nil {
+		return nil, err
+	}
+	return s.repo.ListReviewerExclusions(ctx, x.TeamName)
+}
+
+func (s *Service) ListReviewerExclusions(ctx context.Context, teamName string) ([]models.ReviewerExclusion, error) {
+	ctx, span := tracer.Start(ctx, "Service.ListReviewerExclusions")
+	defer span.End()
+
+	if _, err := s.repo.GetTeamPolicy(ctx, teamName); err != nil {
+		return nil, err
+	}
+	return s.repo.ListReviewerExclusions(ctx, teamName)
+}
+
+// validateRequested checks that every requested reviewer is an active member
+// of the author's team, is not the author, and is neither excluded by the
+// request nor by a team exclusion pair, and that they fit max_reviewers.
+func (s *Service) validateRequested(ctx context.Context, author *models.User, team *models.Team, opts CreateOptions) error {
+	if len(opts.RequestedReviewers) == 0 {
+		return nil
+	}
+	if len(opts.RequestedReviewers) > team.MaxReviewers {
+		return ErrInvalidRequestedReviewer
+	}
+	banned := map[string]bool{author.UserID: true}
+	for _, id := range opts.ExcludedReviewers {
+		banned[id] = true
+	}
+	pairs, err := s.repo.ExcludedReviewersOf(ctx, author.UserID)
+	if err != nil {
+		return err
+	}
+	for _, id := range pairs {
+		banned[id] = true
+	}
+	seen := map[string]bool{}
+	for _, id := range opts.RequestedReviewers {
+		if banned[id] || seen[id] {
+			return ErrInvalidRequestedReviewer
+		}
+		seen[id] = true
+		u, err := s.repo.GetUserByID(ctx, id)
+		if err == sql.ErrNoRows {
+			return ErrInvalidRequestedReviewer
+		}
+		if err != nil {
+			return err
+		}
+		if !u.IsActive || u.TeamName != author.TeamName {
+			return ErrInvalidRequestedReviewer
+		}
+	}
+	return nil
+}
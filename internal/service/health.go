@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+	"github.com/Guardian1221/prsvc/internal/repo"
+)
+
+// DefaultReadinessTimeout bounds each readiness check.
+const DefaultReadinessTimeout = 2 * time.Second
+
+// CheckReadiness pings the database and checks that its schema is at least
+// repo.ExpectedSchemaVersion and not dirty, each within timeout. A newer
+// schema passes, so instances of the previous release stay ready while a
+// rollout migrates. Failures are logged; /readyz is public, so the result
+// only carries a generic message.
+func (s *Service) CheckReadiness(ctx context.Context, timeout time.Duration) models.Readiness {
+	ctx, span := tracer.Start(ctx, "Service.CheckReadiness")
+	defer span.End()
+
+	res := models.Readiness{Status: models.CheckOK, Checks: map[string]models.CheckResult{}}
+	check := func(name, failure string, fn func(ctx context.Context, c *models.CheckResult) error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		start := time.Now()
+		c := models.CheckResult{Status: models.CheckOK}
+		if err := fn(ctx, &c); err != nil {
+			slog.WarnContext(ctx, "readiness check failed", "check", name, "err", err)
+			c.Status = models.CheckFail
+			c.Error = failure
+			var schemaErr schemaError
+			if errors.As(err, &schemaErr) {
+				c.Error = schemaErr.Error()
+			}
+			res.Status = models.CheckFail
+		}
+		c.LatencyMS = float64(time.Since(start).Microseconds()) / 1000
+		res.Checks[name] = c
+	}
+
+	check("database", "database unreachable", func(ctx context.Context, _ *models.CheckResult) error {
+		return s.repo.Ping(ctx)
+	})
+	check("migrations", "schema version unavailable", func(ctx context.Context, c *models.CheckResult) error {
+		version, dirty, err := s.repo.SchemaVersion(ctx)
+		if err != nil {
+			return err
+		}
+		c.Version = &version
+		return checkSchema(version, dirty)
+	})
+	return res
+}
+
+// schemaError describes a schema problem; unlike database errors its text
+// is safe to show on /readyz.
+type schemaError string
+
+func (e schemaError) Error() string {
+	return string(e)
+}
+
+func checkSchema(version int64, dirty bool) error {
+	if dirty {
+		return schemaError(fmt.Sprintf("migration %d is dirty", version))
+	}
+	if version < repo.ExpectedSchemaVersion {
+		return schemaError(fmt.Sprintf("schema at version %d, want at least %d", version, repo.ExpectedSchemaVersion))
+	}
+	return nil
+}
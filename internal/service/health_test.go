@@ -0,0 +1,26 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/Guardian1221/prsvc/internal/repo"
+)
+
+func TestCheckSchema(t *testing.T) {
+	cases := []struct {
+		version int64
+		dirty   bool
+		ok      bool
+	}{
+		{repo.ExpectedSchemaVersion, false, true},
+		{repo.ExpectedSchemaVersion + 1, false, true},
+		{repo.ExpectedSchemaVersion - 1, false, false},
+		{repo.ExpectedSchemaVersion, true, false},
+		{repo.ExpectedSchemaVersion + 1, true, false},
+	}
+	for _, c := range cases {
+		if err := checkSchema(c.version, c.dirty); (err == nil) != c.ok {
+			t.Errorf("checkSchema(%d, %v) = %v, want ok %v", c.version, c.dirty, err, c.ok)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+)
+
+var (
+	ErrInvalidLevel       = errors.New("invalid level")
+	ErrInvalidLevelPolicy = errors.New("invalid level policy")
+)
+
+func validLevel(level string) bool {
+	switch level {
+	case models.LevelJunior, models.LevelMid, models.LevelSenior:
+		return true
+	}
+	return false
+}
+
+func (s *Service) SetUserLevel(ctx context.Context, userID, level string) (*models.User, error) {
+	ctx, span := tracer.Start(ctx, "Service.SetUserLevel")
+	defer span.End()
+
+	if !validLevel(level) {
+		return nil, ErrInvalidLevel
+	}
+	return s.repo.SetUserLevel(ctx, userID, level)
+}
+
+// SetTeamLevelPolicy requires count reviewers at level or above on every PR
+// of the team; a count of 0 drops the requirement.
+func (s *Service) SetTeamLevelPolicy(ctx context.Context, teamName, level string, count int) (*models.Team, error) {
+	ctx, span := tracer.Start(ctx, "Service.SetTeamLevelPolicy")
+	defer span.End()
+
+	if !validLevel(level) || count < 0 || count > MaxReviewersLimit {
+		return nil, ErrInvalidLevelPolicy
+	}
+	if err := s.repo.SetTeamLevelPolicy(ctx, teamName, level, count); err != nil {
+		return nil, err
+	}
+	return s.repo.GetTeam(ctx, teamName)
+}
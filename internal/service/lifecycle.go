@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+	"github.com/Guardian1221/prsvc/internal/repo"
+)
+
+var ErrInvalidTransition = repo.ErrInvalidTransition
+
+// transitions is the PR state machine. DRAFT PRs have no reviewers until
+// they are marked ready; CLOSED PRs give their reviewers back and can be
+// reopened; MERGED is final.
+var transitions = map[string][]string{
+	models.StatusDraft:  {models.StatusOpen, models.StatusClosed},
+	models.StatusOpen:   {models.StatusMerged, models.StatusClosed},
+	models.StatusClosed: {models.StatusOpen},
+}
+
+func canTransition(from, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkReady moves a DRAFT PR to OPEN and assigns its reviewers.
+func (s *Service) MarkReady(ctx context.Context, prID string) (*models.PullRequest, *models.Staffing, error) {
+	ctx, span := tracer.Start(ctx, "Service.MarkReady")
+	defer span.End()
+
+	return s.transition(ctx, prID, models.StatusDraft, models.StatusOpen)
+}
+
+// ReopenPullRequest moves a CLOSED PR back to OPEN with freshly selected
+// reviewers.
+func (s *Service) ReopenPullRequest(ctx context.Context, prID string) (*models.PullRequest, *models.Staffing, error) {
+	ctx, span := tracer.Start(ctx, "Service.ReopenPullRequest")
+	defer span.End()
+
+	return s.transition(ctx, prID, models.StatusClosed, models.StatusOpen)
+}
+
+// ClosePullRequest abandons a DRAFT or OPEN PR and frees its reviewers.
+func (s *Service) ClosePullRequest(ctx context.Context, prID string) (*models.PullRequest, error) {
+	ctx, span := tracer.Start(ctx, "Service.ClosePullRequest")
+	defer span.End()
+
+	pr, _, err := s.transition(ctx, prID, "", models.StatusClosed)
+	return pr, err
+}
+
+// transition moves prID to the to status. A non-empty from additionally
+// requires the PR to currently be in that status.
+func (s *Service) transition(ctx context.Context, prID, from, to string) (*models.PullRequest, *models.Staffing, error) {
+	pr, err := s.repo.GetPullRequest(ctx, prID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if (from != "" && pr.Status != from) || !canTransition(pr.Status, to) {
+		return nil, nil, ErrInvalidTransition
+	}
+
+	var author *models.User
+	var team *models.Team
+	var opts CreateOptions
+	if to == models.StatusOpen {
+		if author, team, err = s.authorAndTeam(ctx, pr.AuthorID); err != nil {
+			return nil, nil, err
+		}
+		if opts.RequestedReviewers, err = s.repo.RequestedReviewersOfPR(ctx, prID); err != nil {
+			return nil, nil, err
+		}
+		if opts.ExcludedReviewers, err = s.repo.ExcludedReviewersOfPR(ctx, prID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var updated *models.PullRequest
+	var staffing *models.Staffing
+	for attempt := 1; ; attempt++ {
+		var reviewers []string
+		if to == models.StatusOpen {
+			reviewers, staffing, err = s.staff(ctx, author, team, opts)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		updated, err = s.repo.SetPullRequestStatus(ctx, prID, pr.Status, to, reviewers, without(reviewers, opts.RequestedReviewers))
+		if err == repo.ErrAtCapacity && attempt < staffAttempts {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		break
+	}
+	if to == models.StatusOpen {
+		s.publishReviewers(updated)
+	}
+	return updated, staffing, nil
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+)
+
+func TestCanTransition(t *testing.T) {
+	cases := []struct {
+		from, to string
+		want     bool
+	}{
+		{models.StatusDraft, models.StatusOpen, true},
+		{models.StatusDraft, models.StatusClosed, true},
+		{models.StatusDraft, models.StatusMerged, false},
+		{models.StatusOpen, models.StatusMerged, true},
+		{models.StatusOpen, models.StatusClosed, true},
+		{models.StatusOpen, models.StatusDraft, false},
+		{models.StatusClosed, models.StatusOpen, true},
+		{models.StatusClosed, models.StatusMerged, false},
+		{models.StatusMerged, models.StatusOpen, false},
+		{models.StatusMerged, models.StatusClosed, false},
+	}
+	for _, c := range cases {
+		if got := canTransition(c.from, c.to); got != c.want {
+			t.Errorf("canTransition(%s, %s) = %v, want %v", c.from, c.to, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+)
+
+// DefaultPairingWindowDays is the stats window when neither the request nor
+// a team sets one.
+const DefaultPairingWindowDays = 30
+
+// MaxPairingWindowDays keeps the history scan to a year.
+const MaxPairingWindowDays = 365
+
+var ErrInvalidPairingWindow = errors.New("invalid pairing window")
+
+// SetTeamPairingWindow sets how far back selection looks for earlier
+// author/reviewer pairs; 0 turns the preference off.
+func (s *Service) SetTeamPairingWindow(ctx context.Context, teamName string, days int) (*models.Team, error) {
+	ctx, span := tracer.Start(ctx, "Service.SetTeamPairingWindow")
+	defer span.End()
+
+	if days < 0 || days > MaxPairingWindowDays {
+		return nil, ErrInvalidPairingWindow
+	}
+	if err := s.repo.SetTeamPairingWindow(ctx, teamName, days); err != nil {
+		return nil, err
+	}
+	return s.repo.GetTeam(ctx, teamName)
+}
+
+// PairingStats reports the pairing diversity of teamName's authors, or of
+// everyone with an empty teamName. A windowDays of 0 uses the team's
+// pairing window, or DefaultPairingWindowDays.
+func (s *Service) PairingStats(ctx context.Context, teamName string, windowDays int) (*models.PairingStats, error) {
+	ctx, span := tracer.Start(ctx, "Service.PairingStats")
+	defer span.End()
+
+	if windowDays < 0 || windowDays > MaxPairingWindowDays {
+		return nil, ErrInvalidPairingWindow
+	}
+	if teamName != "" {
+		team, err := s.repo.GetTeamPolicy(ctx, teamName)
+		if err != nil {
+			return nil, err
+		}
+		if windowDays == 0 {
+			windowDays = team.PairingWindowDays
+		}
+	}
+	if windowDays == 0 {
+		windowDays = DefaultPairingWindowDays
+	}
+	pairs, err := s.repo.ListPairings(ctx, teamName, windowDays)
+	if err != nil {
+		return nil, err
+	}
+	stats := pairingStats(pairs)
+	stats.TeamName = teamName
+	stats.WindowDays = windowDays
+	return stats, nil
+}
+
+func pairingStats(pairs []models.PairingCount) *models.PairingStats {
+	stats := &models.PairingStats{RepeatedPairs: []models.PairingCount{}}
+	for _, p := range pairs {
+		stats.Assignments += p.Reviews
+		stats.DistinctPairs++
+		if p.Reviews > 1 {
+			stats.RepeatedPairs = append(stats.RepeatedPairs, p)
+		}
+	}
+	if stats.Assignments > 0 {
+		stats.Diversity = float64(stats.DistinctPairs) / float64(stats.Assignments)
+	}
+	return stats
+}
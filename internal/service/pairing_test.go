@@ -0,0 +1,28 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+)
+
+func TestPairingStats(t *testing.T) {
+	stats := pairingStats([]models.PairingCount{
+		{AuthorID: "a", ReviewerID: "b", Reviews: 3},
+		{AuthorID: "a", ReviewerID: "c", Reviews: 1},
+	})
+	if stats.Assignments != 4 || stats.DistinctPairs != 2 {
+		t.Fatalf("got %d assignments, %d pairs", stats.Assignments, stats.DistinctPairs)
+	}
+	if stats.Diversity != 0.5 {
+		t.Fatalf("diversity = %v, want 0.5", stats.Diversity)
+	}
+	if len(stats.RepeatedPairs) != 1 || stats.RepeatedPairs[0].ReviewerID != "b" {
+		t.Fatalf("repeated pairs = %+v", stats.RepeatedPairs)
+	}
+
+	empty := pairingStats(nil)
+	if empty.Diversity != 0 || empty.RepeatedPairs == nil {
+		t.Fatalf("empty stats = %+v", empty)
+	}
+}
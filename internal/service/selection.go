@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+	"github.com/Guardian1221/prsvc/internal/repo"
+)
+
+type CreateOptions struct {
+	// Repository and ChangedFiles make selection prefer the CODEOWNERS of
+	// the changed paths before falling back to the author's team.
+	Repository   string
+	ChangedFiles []string
+	// Draft creates the PR as DRAFT without reviewers.
+	Draft bool
+	// RequestedReviewers are assigned first; ExcludedReviewers are never
+	// picked for this PR. Both are stored with the PR, so marking a draft
+	// ready and reopening ask for them again, and later reassignments
+	// respect the exclusions.
+	RequestedReviewers []string
+	ExcludedReviewers  []string
+}
+
+// staffAttempts bounds how often reviewers are picked again after one of
+// them reached max_open_reviews through a concurrent assignment.
+const staffAttempts = 3
+
+// staff picks reviewers for a PR and reports how that went against the
+// team's policy.
+func (s *Service) staff(ctx context.Context, author *models.User, team *models.Team, opts CreateOptions) ([]string, *models.Staffing, error) {
+	if err := s.validateRequested(ctx, author, team, opts); err != nil {
+		return nil, nil, err
+	}
+	reviewers, fallback, err := s.selectInitialReviewers(ctx, author, team, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	staffing := &models.Staffing{
+		MinReviewers: team.MinReviewers,
+		MaxReviewers: team.MaxReviewers,
+		Understaffed: len(reviewers) < team.MinReviewers,
+
+		FallbackReviewers: fallback,
+	}
+	if staffing.Understaffed {
+		limited, err := s.capacityLimited(ctx, author, team, append(reviewers, opts.ExcludedReviewers...))
+		if err != nil {
+			return nil, nil, err
+		}
+		staffing.CapacityLimited = limited
+	}
+	if team.RequiredLevelCount > 0 {
+		have, err := s.repo.CountAtLevel(ctx, reviewers, team.RequiredLevel)
+		if err != nil {
+			return nil, nil, err
+		}
+		staffing.LevelUnmet = have < team.RequiredLevelCount
+	}
+	return reviewers, staffing, nil
+}
+
+// selectInitialReviewers fills up to team.MaxReviewers from the requested
+// reviewers, code owners, reviewers at the team's required level, and the
+// author's team, then tops up to team.MinReviewers from the fallback teams.
+// The second result lists the reviewers taken from fallback teams.
+func (s *Service) selectInitialReviewers(ctx context.Context, author *models.User, team *models.Team, opts CreateOptions) ([]string, []string, error) {
+	limit := team.MaxReviewers
+	reviewers := append([]string{}, opts.RequestedReviewers...)
+	exclude := append([]string{author.UserID}, opts.ExcludedReviewers...)
+	exclude = append(exclude, reviewers...)
+	fallback := []string{}
+
+	if len(opts.ChangedFiles) > 0 && len(reviewers) < limit {
+		owners, err := s.codeOwnersOf(ctx, opts.Repository, opts.ChangedFiles)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(owners) > 0 {
+			picked, err := s.repo.SelectCandidates(ctx, repo.CandidateFilter{
+				Teams:   owners,
+				Users:   owners,
+				Exclude: exclude,
+				Limit:   limit - len(reviewers),
+
+				Author:            author.UserID,
+				PairingWindowDays: team.PairingWindowDays,
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			reviewers = append(reviewers, picked...)
+			exclude = append(exclude, picked...)
+		}
+	}
+
+	if team.RequiredLevelCount > 0 {
+		have, err := s.repo.CountAtLevel(ctx, reviewers, team.RequiredLevel)
+		if err != nil {
+			return nil, nil, err
+		}
+		for i, t := range append([]string{author.TeamName}, team.FallbackTeams...) {
+			need := min(team.RequiredLevelCount-have, limit-len(reviewers))
+			if need <= 0 {
+				break
+			}
+			picked, err := s.repo.SelectCandidates(ctx, repo.CandidateFilter{
+				Teams:   []string{t},
+				Exclude: exclude,
+				Limit:   need,
+
+				Author:            author.UserID,
+				PairingWindowDays: team.PairingWindowDays,
+				MinLevel:          team.RequiredLevel,
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			reviewers = append(reviewers, picked...)
+			exclude = append(exclude, picked...)
+			if i > 0 {
+				fallback = append(fallback, picked...)
+			}
+			have += len(picked)
+		}
+	}
+
+	if len(reviewers) < limit {
+		picked, err := s.repo.SelectCandidates(ctx, repo.CandidateFilter{
+			Teams:   []string{author.TeamName},
+			Exclude: exclude,
+			Limit:   limit - len(reviewers),
+
+			Author:            author.UserID,
+			PairingWindowDays: team.PairingWindowDays,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		reviewers = append(reviewers, picked...)
+		exclude = append(exclude, picked...)
+	}
+
+	for _, fb := range team.FallbackTeams {
+		if len(reviewers) >= team.MinReviewers {
+			break
+		}
+		picked, err := s.repo.SelectCandidates(ctx, repo.CandidateFilter{
+			Teams:   []string{fb},
+			Exclude: exclude,
+			Limit:   team.MinReviewers - len(reviewers),
+
+			Author:            author.UserID,
+			PairingWindowDays: team.PairingWindowDays,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		reviewers = append(reviewers, picked...)
+		exclude = append(exclude, picked...)
+		fallback = append(fallback, picked...)
+	}
+	return reviewers, fallback, nil
+}
+
+// capacityLimited reports whether the author's team or its fallbacks still
+// had eligible reviewers who were skipped only for being at capacity.
+// exclude lists the users that were not eligible anyway.
+func (s *Service) capacityLimited(ctx context.Context, author *models.User, team *models.Team, exclude []string) (bool, error) {
+	picked, err := s.repo.SelectCandidates(ctx, repo.CandidateFilter{
+		Teams:          append([]string{author.TeamName}, team.FallbackTeams...),
+		Exclude:        append([]string{author.UserID}, exclude...),
+		Limit:          1,
+		IgnoreCapacity: true,
+		Author:         author.UserID,
+	})
+	if err != nil {
+		return false, err
+	}
+	return len(picked) > 0, nil
+}
+
+// without returns the elements of list that are not in drop.
+func without(list, drop []string) []string {
+	skip := make(map[string]bool, len(drop))
+	for _, id := range drop {
+		skip[id] = true
+	}
+	res := []string{}
+	for _, id := range list {
+		if !skip[id] {
+			res = append(res, id)
+		}
+	}
+	return res
+}
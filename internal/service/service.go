@@ -2,22 +2,77 @@ package service
 
 import (
 	"context"
-	"database/sql"
+	"errors"
+	"time"
 
 	"github.com/Guardian1221/prsvc/internal/models"
 	"github.com/Guardian1221/prsvc/internal/repo"
+	"go.opentelemetry.io/otel"
 )
 
+// tracer starts a span for every exported Service call.
+var tracer = otel.Tracer("github.com/Guardian1221/prsvc/internal/service")
+
 type Service struct {
-	repo *repo.PostgresRepo
+	repo      *repo.PostgresRepo
+	publisher ReviewerPublisher
+	notifier  ReminderNotifier
+	recorder  Recorder
+	now       func() time.Time
+
+	bootstrapHash string
+}
+
+// Recorder counts domain events, e.g. for metrics.
+type Recorder interface {
+	PRCreated()
+	Reassigned(reason string)
+	NoCandidate(reason string)
+	Merged(external bool)
+}
+
+type nopRecorder struct{}
+
+func (nopRecorder) PRCreated()         {}
+func (nopRecorder) Reassigned(string)  {}
+func (nopRecorder) NoCandidate(string) {}
+func (nopRecorder) Merged(bool)        {}
+
+func (s *Service) SetRecorder(r Recorder) {
+	s.recorder = r
+}
+
+// SetClock replaces the time source of the scheduled jobs, e.g. to run them
+// at a fixed time in tests.
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// ReviewerPublisher is told about a PR's reviewers after they were assigned
+// or changed. It must not block the request.
+type ReviewerPublisher interface {
+	PublishReviewers(pr models.PullRequest)
+}
+
+func (s *Service) SetReviewerPublisher(p ReviewerPublisher) {
+	s.publisher = p
+}
+
+func (s *Service) publishReviewers(pr *models.PullRequest) {
+	if s.publisher != nil && pr != nil {
+		s.publisher.PublishReviewers(*pr)
+	}
 }
 
 func (s *Service) GetTeam(ctx context.Context, name string) (*models.Team, error) {
+	ctx, span := tracer.Start(ctx, "Service.GetTeam")
+	defer span.End()
+
 	return s.repo.GetTeam(ctx, name)
 }
 
 func NewService(r *repo.PostgresRepo) *Service {
-	return &Service{repo: r}
+	return &Service{repo: r, notifier: logNotifier{}, recorder: nopRecorder{}, now: time.Now}
 }
 
 var (
@@ -26,42 +81,239 @@ var (
 	ErrPRMerged    = repo.ErrPRMerged
 	ErrNotAssigned = repo.ErrNotAssigned
 	ErrNoCandidate = repo.ErrNoCandidate
+
+	ErrPRNotOpen          = repo.ErrPRNotOpen
+	ErrNotEnoughApprovals = repo.ErrNotEnoughApprovals
+)
+
+const (
+	DefaultMinReviewers = 1
+	DefaultMaxReviewers = 2
+	// MaxReviewersLimit caps max_reviewers so a typo cannot assign a whole
+	// department to one PR.
+	MaxReviewersLimit = 10
 )
 
+var ErrInvalidReviewerPolicy = errors.New("invalid reviewer policy")
+
+// CreateTeam applies the default reviewer policy when the request does not
+// set max_reviewers.
 func (s *Service) CreateTeam(ctx context.Context, t models.Team) error {
+	ctx, span := tracer.Start(ctx, "Service.CreateTeam")
+	defer span.End()
+
+	t.MinReviewers, t.MaxReviewers = defaultReviewerPolicy(t.MinReviewers, t.MaxReviewers)
+	if err := validateReviewerPolicy(t.MinReviewers, t.MaxReviewers); err != nil {
+		return err
+	}
 	return s.repo.CreateTeam(ctx, t)
 }
 
-func (s *Service) CreatePullRequest(ctx context.Context, pr models.PullRequest) (*models.PullRequest, error) {
-	_, err := s.repo.GetUserByID(ctx, pr.AuthorID)
-	if err != nil {
+// defaultReviewerPolicy fills in an unset max_reviewers, raising it to
+// min_reviewers when only the minimum was given.
+func defaultReviewerPolicy(minReviewers, maxReviewers int) (int, int) {
+	if maxReviewers != 0 {
+		return minReviewers, maxReviewers
+	}
+	if minReviewers == 0 {
+		minReviewers = DefaultMinReviewers
+	}
+	return minReviewers, max(DefaultMaxReviewers, minReviewers)
+}
+
+func (s *Service) SetTeamReviewerPolicy(ctx context.Context, teamName string, minReviewers, maxReviewers int) (*models.Team, error) {
+	ctx, span := tracer.Start(ctx, "Service.SetTeamReviewerPolicy")
+	defer span.End()
+
+	if err := validateReviewerPolicy(minReviewers, maxReviewers); err != nil {
 		return nil, err
 	}
+	if err := s.repo.SetTeamReviewerPolicy(ctx, teamName, minReviewers, maxReviewers); err != nil {
+		return nil, err
+	}
+	return s.repo.GetTeam(ctx, teamName)
+}
+
+func validateReviewerPolicy(minReviewers, maxReviewers int) error {
+	if minReviewers < 0 || maxReviewers < 1 || maxReviewers > MaxReviewersLimit || minReviewers > maxReviewers {
+		return ErrInvalidReviewerPolicy
+	}
+	return nil
+}
 
-	reviewers, err := s.repo.SelectInitialReviewers(ctx, pr.AuthorID, 2)
+// CreatePullRequest assigns up to the team's max_reviewers. Staffing tells
+// the caller whether the team's min_reviewers could be met; it is nil for a
+// draft, which gets no reviewers until it is marked ready.
+func (s *Service) CreatePullRequest(ctx context.Context, pr models.PullRequest, opts CreateOptions) (*models.PullRequest, *models.Staffing, error) {
+	ctx, span := tracer.Start(ctx, "Service.CreatePullRequest")
+	defer span.End()
+
+	author, team, err := s.authorAndTeam(ctx, pr.AuthorID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	if err := s.repo.CreatePullRequestWithReviewers(ctx, pr, reviewers); err != nil {
-		return nil, err
+	var staffing *models.Staffing
+	pr.Status = models.StatusDraft
+	if !opts.Draft {
+		pr.Status = models.StatusOpen
+	}
+	// A draft is only staffed once it is marked ready, but its requested
+	// reviewers are checked now so a bad request fails at creation.
+	if opts.Draft {
+		if err := s.validateRequested(ctx, author, team, opts); err != nil {
+			return nil, nil, err
+		}
+	}
+	for attempt := 1; ; attempt++ {
+		var reviewers []string
+		if !opts.Draft {
+			reviewers, staffing, err = s.staff(ctx, author, team, opts)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		// Requested reviewers are assigned even past their cap.
+		err = s.repo.CreatePullRequestWithReviewers(ctx, pr, reviewers, without(reviewers, opts.RequestedReviewers), opts.RequestedReviewers, opts.ExcludedReviewers)
+		if err == repo.ErrAtCapacity && attempt < staffAttempts {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		break
 	}
 
 	created, err := s.repo.GetPullRequest(ctx, pr.PullRequestID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.recorder.PRCreated()
+	if !opts.Draft {
+		s.publishReviewers(created)
+	}
+	return created, staffing, nil
+}
+
+func (s *Service) authorAndTeam(ctx context.Context, authorID string) (*models.User, *models.Team, error) {
+	author, err := s.repo.GetUserByID(ctx, authorID)
+	if err != nil {
+		return nil, nil, err
+	}
+	team, err := s.repo.GetTeamPolicy(ctx, author.TeamName)
+	if err != nil {
+		return nil, nil, err
+	}
+	return author, team, nil
+}
+
+// MergePullRequest refuses with ErrNotEnoughApprovals until the PR has the
+// required_approvals of the author's team.
+func (s *Service) MergePullRequest(ctx context.Context, prID string) (*models.PullRequest, error) {
+	ctx, span := tracer.Start(ctx, "Service.MergePullRequest")
+	defer span.End()
+
+	pr, err := s.repo.GetPullRequest(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+	if pr.Status == models.StatusMerged {
+		return pr, nil
+	}
+	if !canTransition(pr.Status, models.StatusMerged) {
+		return nil, ErrInvalidTransition
+	}
+	_, team, err := s.authorAndTeam(ctx, pr.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+	merged, changed, err := s.repo.MergePullRequest(ctx, prID, team.RequiredApprovals)
 	if err != nil {
 		return nil, err
 	}
+	if changed {
+		s.recorder.Merged(false)
+	}
+	return merged, nil
+}
+
+// RecordExternalMerge marks a PR merged on the code host as merged here.
+// The host already merged it, so approvals are not checked.
+func (s *Service) RecordExternalMerge(ctx context.Context, prID string) (*models.PullRequest, error) {
+	ctx, span := tracer.Start(ctx, "Service.RecordExternalMerge")
+	defer span.End()
 
-	return created, nil
+	pr, err := s.repo.GetPullRequest(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+	if pr.Status == models.StatusMerged {
+		return pr, nil
+	}
+	if !canTransition(pr.Status, models.StatusMerged) {
+		return nil, ErrInvalidTransition
+	}
+	merged, changed, err := s.repo.MergePullRequest(ctx, prID, 0)
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		s.recorder.Merged(true)
+	}
+	return merged, nil
 }
 
-func (s *Service) ReassignReviewer(ctx context.Context, prID string, oldReviewer string) (string, *models.PullRequest, error) {
-	newID, pr, err := s.repo.ReassignReviewer(ctx, prID, oldReviewer)
+// ReassignReviewer replaces oldReviewer on prID. reason is one of the
+// models.Reason* values and is kept in the PR's reassignment history.
+func (s *Service) ReassignReviewer(ctx context.Context, prID string, oldReviewer string, reason string) (*models.Reassignment, error) {
+	ctx, span := tracer.Start(ctx, "Service.ReassignReviewer")
+	defer span.End()
+
+	res, err := s.repo.ReassignReviewer(ctx, prID, oldReviewer, reason)
+	for attempt := 2; err == repo.ErrAtCapacity && attempt <= staffAttempts; attempt++ {
+		res, err = s.repo.ReassignReviewer(ctx, prID, oldReviewer, reason)
+	}
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return "", nil, err
+		if err == ErrNoCandidate {
+			s.recorder.NoCandidate(reason)
 		}
-		return "", nil, err
+		return nil, err
+	}
+	s.recorder.Reassigned(reason)
+	s.publishReviewers(res.PR)
+	return res, nil
+}
+
+var ErrInvalidCapacity = errors.New("invalid max_open_reviews")
+
+// SetUserMaxOpenReviews sets the user's review cap; nil removes it.
+func (s *Service) SetUserMaxOpenReviews(ctx context.Context, userID string, max *int) (*models.User, error) {
+	ctx, span := tracer.Start(ctx, "Service.SetUserMaxOpenReviews")
+	defer span.End()
+
+	if max != nil && *max < 0 {
+		return nil, ErrInvalidCapacity
+	}
+	return s.repo.SetUserMaxOpenReviews(ctx, userID, max)
+}
+
+func (s *Service) ListReassignments(ctx context.Context, prID string) ([]models.ReassignmentRecord, error) {
+	ctx, span := tracer.Start(ctx, "Service.ListReassignments")
+	defer span.End()
+
+	if _, err := s.repo.GetPullRequest(ctx, prID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListReassignments(ctx, prID)
+}
+
+func (s *Service) SetTeamFallbacks(ctx context.Context, teamName string, fallbacks []string) (*models.Team, error) {
+	ctx, span := tracer.Start(ctx, "Service.SetTeamFallbacks")
+	defer span.End()
+
+	if err := s.repo.SetTeamFallbacks(ctx, teamName, fallbacks); err != nil {
+		return nil, err
 	}
-	return newID, pr, nil
+	return s.repo.GetTeam(ctx, teamName)
 }
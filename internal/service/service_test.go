@@ -0,0 +1,49 @@
+package service
+
+import "testing"
+
+func TestDefaultReviewerPolicy(t *testing.T) {
+	cases := []struct {
+		min, max         int
+		wantMin, wantMax int
+	}{
+		{0, 0, DefaultMinReviewers, DefaultMaxReviewers},
+		{3, 0, 3, 3},
+		{1, 0, 1, DefaultMaxReviewers},
+		{0, 4, 0, 4},
+		{2, 5, 2, 5},
+	}
+	for _, c := range cases {
+		gotMin, gotMax := defaultReviewerPolicy(c.min, c.max)
+		if gotMin != c.wantMin || gotMax != c.wantMax {
+			t.Errorf("defaultReviewerPolicy(%d, %d) = %d, %d, want %d, %d", c.min, c.max, gotMin, gotMax, c.wantMin, c.wantMax)
+		}
+		if err := validateReviewerPolicy(gotMin, gotMax); err != nil {
+			t.Errorf("defaults for (%d, %d) rejected: %v", c.min, c.max, err)
+		}
+	}
+}
+
+func TestValidateReviewerPolicy(t *testing.T) {
+	cases := []struct {
+		min, max int
+		ok       bool
+	}{
+		{0, 1, true},
+		{1, 2, true},
+		{MaxReviewersLimit, MaxReviewersLimit, true},
+		{-1, 2, false},
+		{0, 0, false},
+		{3, 2, false},
+		{1, MaxReviewersLimit + 1, false},
+	}
+	for _, c := range cases {
+		err := validateReviewerPolicy(c.min, c.max)
+		if (err == nil) != c.ok {
+			t.Errorf("validateReviewerPolicy(%d, %d) = %v, want ok=%v", c.min, c.max, err, c.ok)
+		}
+		if err != nil && err != ErrInvalidReviewerPolicy {
+			t.Errorf("validateReviewerPolicy(%d, %d) = %v, want ErrInvalidReviewerPolicy", c.min, c.max, err)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+)
+
+var ErrInvalidSLA = errors.New("invalid review SLA")
+
+// ReminderNotifier receives one reminder per assignment that breached its
+// team's review SLA.
+type ReminderNotifier interface {
+	RemindOverdue(ctx context.Context, r models.OverdueReview) error
+}
+
+type logNotifier struct{}
+
+func (logNotifier) RemindOverdue(ctx context.Context, r models.OverdueReview) error {
+	slog.InfoContext(ctx, "review overdue", "reviewer", r.ReviewerID, "pull_request_id", r.PullRequestID, "overdue_minutes", r.OverdueMinutes)
+	return nil
+}
+
+func (s *Service) SetReminderNotifier(n ReminderNotifier) {
+	s.notifier = n
+}
+
+func (s *Service) SetTeamReviewSLA(ctx context.Context, teamName string, minutes int) (*models.Team, error) {
+	ctx, span := tracer.Start(ctx, "Service.SetTeamReviewSLA")
+	defer span.End()
+
+	if minutes <= 0 {
+		return nil, ErrInvalidSLA
+	}
+	if err := s.repo.SetTeamReviewSLA(ctx, teamName, minutes); err != nil {
+		return nil, err
+	}
+	return s.repo.GetTeam(ctx, teamName)
+}
+
+// ListOverdueReviews returns assignments without a first response that are
+// past their team's SLA at now. The SLA only counts the reviewer's working
+// hours. An empty teamName covers all teams.
+func (s *Service) ListOverdueReviews(ctx context.Context, teamName string, now time.Time) ([]models.OverdueReview, error) {
+	ctx, span := tracer.Start(ctx, "Service.ListOverdueReviews")
+	defer span.End()
+
+	pending, err := s.repo.ListPendingReviews(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	overdue := []models.OverdueReview{}
+	for _, p := range pending {
+		cal := reviewerCalendar(p)
+		due := cal.AddWorking(p.AssignedAt, time.Duration(p.SLAMinutes)*time.Minute)
+		if !now.After(due) {
+			continue
+		}
+		overdue = append(overdue, models.OverdueReview{
+			PendingReview:  p,
+			DueAt:          due,
+			OverdueMinutes: int(workingSince(cal, due, now) / time.Minute),
+		})
+	}
+	return overdue, nil
+}
+
+// SendOverdueReminders notifies about every breach not reminded yet. A
+// failed reminder is retried on the next run without holding back the
+// others; all failures are returned joined. It is run periodically by the
+// scheduler.
+func (s *Service) SendOverdueReminders(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "Service.SendOverdueReminders")
+	defer span.End()
+
+	overdue, err := s.ListOverdueReviews(ctx, "", s.now())
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, o := range overdue {
+		if o.RemindedAt != nil {
+			continue
+		}
+		if err := s.notifier.RemindOverdue(ctx, o); err != nil {
+			slog.ErrorContext(ctx, "overdue reminder failed", "pull_request_id", o.PullRequestID, "reviewer", o.ReviewerID, "err", err)
+			errs = append(errs, err)
+			continue
+		}
+		if err := s.repo.MarkReminded(ctx, o.PullRequestID, o.ReviewerID); err != nil {
+			slog.ErrorContext(ctx, "overdue mark reminded failed", "pull_request_id", o.PullRequestID, "reviewer", o.ReviewerID, "err", err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
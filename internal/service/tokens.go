@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"github.com/Guardian1221/prsvc/internal/auth"
+	"github.com/Guardian1221/prsvc/internal/models"
+)
+
+var ErrInvalidTokenRequest = errors.New("invalid token request")
+
+// SetBootstrapAdminToken accepts token as an admin token without it being
+// stored, so the first real tokens can be issued. An empty token disables it.
+func (s *Service) SetBootstrapAdminToken(token string) {
+	s.bootstrapHash = ""
+	if token != "" {
+		s.bootstrapHash = auth.Hash(token)
+	}
+}
+
+// IssueAPIToken creates a token and returns it in plain text together with
+// its stored description. A non-empty userID binds the token to that user.
+func (s *Service) IssueAPIToken(ctx context.Context, name string, scope auth.Scope, userID string) (string, *models.APIToken, error) {
+	ctx, span := tracer.Start(ctx, "Service.IssueAPIToken")
+	defer span.End()
+
+	if name == "" || !scope.Valid() {
+		return "", nil, ErrInvalidTokenRequest
+	}
+	t := models.APIToken{Name: name, Scope: string(scope)}
+	if userID != "" {
+		if _, err := s.repo.GetUserByID(ctx, userID); err != nil {
+			return "", nil, err
+		}
+		t.UserID = &userID
+	}
+	token, err := auth.NewToken()
+	if err != nil {
+		return "", nil, err
+	}
+	created, err := s.repo.CreateAPIToken(ctx, t, auth.Hash(token))
+	if err != nil {
+		return "", nil, err
+	}
+	return token, created, nil
+}
+
+func (s *Service) RevokeAPIToken(ctx context.Context, id int64) (*models.APIToken, error) {
+	ctx, span := tracer.Start(ctx, "Service.RevokeAPIToken")
+	defer span.End()
+
+	return s.repo.RevokeAPIToken(ctx, id)
+}
+
+func (s *Service) ListAPITokens(ctx context.Context) ([]models.APIToken, error) {
+	ctx, span := tracer.Start(ctx, "Service.ListAPITokens")
+	defer span.End()
+
+	return s.repo.ListAPITokens(ctx)
+}
+
+// Authenticate resolves a bearer token to its caller. Unknown and revoked
+// tokens give auth.ErrInvalidToken.
+func (s *Service) Authenticate(ctx context.Context, token string) (*auth.Principal, error) {
+	ctx, span := tracer.Start(ctx, "Service.Authenticate")
+	defer span.End()
+
+	hash := auth.Hash(token)
+	if s.bootstrapHash != "" && subtle.ConstantTimeCompare([]byte(hash), []byte(s.bootstrapHash)) == 1 {
+		return &auth.Principal{Subject: "bootstrap", Scope: auth.ScopeAdmin}, nil
+	}
+	t, err := s.repo.GetActiveAPIToken(ctx, hash)
+	if err == sql.ErrNoRows {
+		return nil, auth.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	p := &auth.Principal{Subject: "token:" + strconv.FormatInt(t.ID, 10), Scope: auth.Scope(t.Scope)}
+	if t.UserID != nil {
+		p.UserID = *t.UserID
+	}
+	return p, nil
+}
@@ -0,0 +1,45 @@
+package service
+
+import "context"
+
+func (s *Service) SetExternalUser(ctx context.Context, provider, externalID, userID string) error {
+	ctx, span := tracer.Start(ctx, "Service.SetExternalUser")
+	defer span.End()
+
+	return s.repo.SetExternalUser(ctx, provider, externalID, userID)
+}
+
+func (s *Service) ResolveExternalUser(ctx context.Context, provider, externalID string) (string, error) {
+	ctx, span := tracer.Start(ctx, "Service.ResolveExternalUser")
+	defer span.End()
+
+	return s.repo.GetUserIDByExternal(ctx, provider, externalID)
+}
+
+func (s *Service) ClaimDelivery(ctx context.Context, provider, deliveryID string) (bool, error) {
+	ctx, span := tracer.Start(ctx, "Service.ClaimDelivery")
+	defer span.End()
+
+	return s.repo.ClaimDelivery(ctx, provider, deliveryID)
+}
+
+func (s *Service) ReleaseDelivery(ctx context.Context, provider, deliveryID string) error {
+	ctx, span := tracer.Start(ctx, "Service.ReleaseDelivery")
+	defer span.End()
+
+	return s.repo.ReleaseDelivery(ctx, provider, deliveryID)
+}
+
+func (s *Service) ExternalUserID(ctx context.Context, provider, userID string) (string, error) {
+	ctx, span := tracer.Start(ctx, "Service.ExternalUserID")
+	defer span.End()
+
+	return s.repo.GetExternalIDByUser(ctx, provider, userID)
+}
+
+func (s *Service) SaveDeadLetter(ctx context.Context, provider, prID string, reviewers []string, attempts int, lastErr string) error {
+	ctx, span := tracer.Start(ctx, "Service.SaveDeadLetter")
+	defer span.End()
+
+	return s.repo.SaveDeadLetter(ctx, provider, prID, reviewers, attempts, lastErr)
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+)
+
+var (
+	ErrInvalidVerdict   = errors.New("invalid verdict")
+	ErrInvalidApprovals = errors.New("invalid required_approvals")
+)
+
+func (s *Service) SubmitVerdict(ctx context.Context, prID, userID, verdict string) (*models.PullRequest, error) {
+	ctx, span := tracer.Start(ctx, "Service.SubmitVerdict")
+	defer span.End()
+
+	if verdict != models.VerdictApproved && verdict != models.VerdictChangesRequested {
+		return nil, ErrInvalidVerdict
+	}
+	return s.repo.SubmitVerdict(ctx, prID, userID, verdict)
+}
+
+func (s *Service) SetTeamRequiredApprovals(ctx context.Context, teamName string, required int) (*models.Team, error) {
+	ctx, span := tracer.Start(ctx, "Service.SetTeamRequiredApprovals")
+	defer span.End()
+
+	if required < 0 || required > MaxReviewersLimit {
+		return nil, ErrInvalidApprovals
+	}
+	if err := s.repo.SetTeamRequiredApprovals(ctx, teamName, required); err != nil {
+		return nil, err
+	}
+	return s.repo.GetTeam(ctx, teamName)
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+	"github.com/Guardian1221/prsvc/internal/workhours"
+)
+
+var ErrInvalidWorkingHours = errors.New("invalid working hours")
+
+// SetUserWorkingHours replaces the user's time zone and weekly working
+// hours. Selection prefers users inside their working hours, and SLA timers
+// only run while the reviewer is working. The zone must be known to both Go
+// and Postgres; Go alone also accepts "Local" and "", which mean the
+// server's zone.
+func (s *Service) SetUserWorkingHours(ctx context.Context, userID string, wh models.WorkingHours) (*models.User, error) {
+	ctx, span := tracer.Start(ctx, "Service.SetUserWorkingHours")
+	defer span.End()
+
+	if wh.TimeZone == "" || wh.TimeZone == "Local" {
+		return nil, ErrInvalidWorkingHours
+	}
+	if _, err := workhours.New(wh.TimeZone, wh.WorkStartMinute, wh.WorkEndMinute, workhours.Days(wh.WorkDays)); err != nil {
+		return nil, ErrInvalidWorkingHours
+	}
+	if wh.WorkDays == 0 || wh.WorkDays >= 1<<7 {
+		return nil, ErrInvalidWorkingHours
+	}
+	known, err := s.repo.KnownTimeZone(ctx, wh.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+	if !known {
+		return nil, ErrInvalidWorkingHours
+	}
+	return s.repo.SetUserWorkingHours(ctx, userID, wh)
+}
+
+// reviewerCalendar is the working calendar of a pending review's reviewer.
+// A calendar that cannot be loaded counts wall-clock time.
+func reviewerCalendar(p models.PendingReview) workhours.Calendar {
+	cal, err := workhours.New(p.ReviewerTimeZone, p.ReviewerStartMinute, p.ReviewerEndMinute, workhours.Days(p.ReviewerDays))
+	if err != nil {
+		return workhours.Calendar{Location: time.UTC}
+	}
+	return cal
+}
+
+// workingSince is the reviewer's working time between from and to.
+func workingSince(cal workhours.Calendar, from, to time.Time) time.Duration {
+	if cal.Days == 0 {
+		return to.Sub(from)
+	}
+	return cal.WorkingDuration(from, to)
+}
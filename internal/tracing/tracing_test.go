@@ -0,0 +1,49 @@
+package tracing
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSetup(t *testing.T) {
+	if _, err := Setup(context.Background(), "zipkin", ""); err == nil {
+		t.Fatal("unknown exporter accepted")
+	}
+	shutdown, err := Setup(context.Background(), ExporterNone, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	fields := otel.GetTextMapPropagator().Fields()
+	if !slices.Contains(fields, "traceparent") {
+		t.Fatalf("propagator fields %v", fields)
+	}
+}
+
+func TestNewProviderNamesService(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	tp := NewProvider(sdktrace.WithSyncer(exp))
+	_, span := tp.Tracer("test").Start(context.Background(), "op")
+	span.End()
+
+	spans := exp.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans", len(spans))
+	}
+	found := false
+	for _, kv := range spans[0].Resource.Attributes() {
+		if kv.Key == "service.name" && kv.Value.AsString() == ServiceName {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("resource %v lacks service.name", spans[0].Resource.Attributes())
+	}
+}
@@ -0,0 +1,122 @@
+package vcs
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+)
+
+// GitHubPullRequestID is the prsvc ID of a pull request, using GitHub's own
+// "owner/repo#number" reference notation.
+func GitHubPullRequestID(owner, repo string, number int64) string {
+	return fmt.Sprintf("%s:%s/%s#%d", ProviderGitHub, owner, repo, number)
+}
+
+// GitHubClient requests pull request reviewers through the GitHub REST API.
+// GitHub users are mapped by login, which is what the API takes.
+type GitHubClient struct {
+	baseURL string
+	token   string
+	users   ExternalUserResolver
+	http    *http.Client
+}
+
+func NewGitHubClient(baseURL, token string, users ExternalUserResolver) *GitHubClient {
+	return &GitHubClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		users:   users,
+		http:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (c *GitHubClient) Provider() string {
+	return ProviderGitHub
+}
+
+// RequestReviewers requests review from the PR's assigned reviewers.
+// GitHub only adds to the requested reviewers, so a reviewer prsvc replaced
+// stays requested until removed on GitHub. Reviewers without a GitHub
+// mapping are left out, and nothing is sent when none is mapped.
+func (c *GitHubClient) RequestReviewers(ctx context.Context, pr models.PullRequest) error {
+	owner, repo, number, ok := parseGitHubPullRequestID(pr.PullRequestID)
+	if !ok {
+		return ErrNotHosted
+	}
+
+	logins := []string{}
+	for _, userID := range pr.AssignedReviewers {
+		login, err := c.users.ExternalUserID(ctx, ProviderGitHub, userID)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		logins = append(logins, login)
+	}
+	if len(logins) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(map[string]any{"reviewers": logins})
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/requested_reviewers", c.baseURL, owner, repo, number)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	switch {
+	case resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("github: %s: %s", resp.Status, msg)
+	case resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0":
+		// GitHub reports an exhausted rate limit as 403.
+		return fmt.Errorf("github: %s: %s", resp.Status, msg)
+	default:
+		return Permanent(fmt.Errorf("github: %s: %s", resp.Status, msg))
+	}
+}
+
+func parseGitHubPullRequestID(prID string) (owner, repo string, number int64, ok bool) {
+	rest, found := strings.CutPrefix(prID, ProviderGitHub+":")
+	if !found {
+		return "", "", 0, false
+	}
+	path, num, found := strings.Cut(rest, "#")
+	if !found {
+		return "", "", 0, false
+	}
+	owner, repo, found = strings.Cut(path, "/")
+	if !found || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return "", "", 0, false
+	}
+	number, err := strconv.ParseInt(num, 10, 64)
+	if err != nil {
+		return "", "", 0, false
+	}
+	return owner, repo, number, true
+}
@@ -0,0 +1,98 @@
+package vcs
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+var (
+	ErrInvalidToken   = errors.New("invalid webhook token")
+	ErrInvalidPayload = errors.New("invalid webhook payload")
+)
+
+// VerifyGitLabToken compares the X-Gitlab-Token header with the secret
+// configured on the GitLab webhook.
+func VerifyGitLabToken(h http.Header, secret string) error {
+	got := h.Get("X-Gitlab-Token")
+	if secret == "" || subtle.ConstantTimeCompare([]byte(got), []byte(secret)) != 1 {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+// GitLabPullRequestID is the prsvc ID of a merge request, using GitLab's own
+// "project!iid" reference notation.
+func GitLabPullRequestID(projectID, iid int64) string {
+	return fmt.Sprintf("%s:%d!%d", ProviderGitLab, projectID, iid)
+}
+
+type gitlabMergeRequestHook struct {
+	ObjectKind string `json:"object_kind"`
+	Project    struct {
+		ID int64 `json:"id"`
+	} `json:"project"`
+	ObjectAttributes struct {
+		IID      int64  `json:"iid"`
+		Title    string `json:"title"`
+		AuthorID int64  `json:"author_id"`
+		Action   string `json:"action"`
+		Draft    bool   `json:"draft"`
+	} `json:"object_attributes"`
+	Changes struct {
+		Draft *struct {
+			Previous bool `json:"previous"`
+			Current  bool `json:"current"`
+		} `json:"draft"`
+	} `json:"changes"`
+}
+
+// ParseGitLabEvent decodes a GitLab webhook delivery. Anything other than a
+// merge request open, reopen, merge, close or leaving draft comes back as
+// EventIgnored.
+func ParseGitLabEvent(h http.Header, body []byte) (Event, error) {
+	ev := Event{
+		Provider:   ProviderGitLab,
+		DeliveryID: h.Get("Idempotency-Key"),
+		Kind:       EventIgnored,
+	}
+	if ev.DeliveryID == "" {
+		ev.DeliveryID = h.Get("X-Gitlab-Event-UUID")
+	}
+
+	var hook gitlabMergeRequestHook
+	if err := json.Unmarshal(body, &hook); err != nil {
+		return Event{}, ErrInvalidPayload
+	}
+	if hook.ObjectKind != "merge_request" {
+		return ev, nil
+	}
+	attrs := hook.ObjectAttributes
+	if hook.Project.ID == 0 || attrs.IID == 0 {
+		return Event{}, ErrInvalidPayload
+	}
+
+	ev.PullRequestID = GitLabPullRequestID(hook.Project.ID, attrs.IID)
+	ev.Title = attrs.Title
+	ev.AuthorExternalID = strconv.FormatInt(attrs.AuthorID, 10)
+	ev.Draft = attrs.Draft
+
+	switch attrs.Action {
+	case "open":
+		ev.Kind = EventOpened
+	case "reopen":
+		ev.Kind = EventReopened
+	case "merge":
+		ev.Kind = EventMerged
+	case "close":
+		ev.Kind = EventClosed
+	case "update":
+		if d := hook.Changes.Draft; d != nil && d.Previous && !d.Current {
+			ev.Kind = EventReady
+		}
+	}
+	return ev, nil
+}
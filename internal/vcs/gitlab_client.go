@@ -0,0 +1,119 @@
+package vcs
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+)
+
+// ExternalUserResolver maps a prsvc user ID to the provider's user ID.
+type ExternalUserResolver interface {
+	ExternalUserID(ctx context.Context, provider, userID string) (string, error)
+}
+
+// GitLabClient sets merge request reviewers through the GitLab REST API.
+type GitLabClient struct {
+	baseURL string
+	token   string
+	users   ExternalUserResolver
+	http    *http.Client
+}
+
+func NewGitLabClient(baseURL, token string, users ExternalUserResolver) *GitLabClient {
+	return &GitLabClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		users:   users,
+		http:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (c *GitLabClient) Provider() string {
+	return ProviderGitLab
+}
+
+// RequestReviewers replaces the MR's reviewers with the PR's assigned
+// reviewers. Reviewers without a GitLab mapping are left out; when none is
+// mapped nothing is sent, as an empty list would clear the MR's reviewers.
+func (c *GitLabClient) RequestReviewers(ctx context.Context, pr models.PullRequest) error {
+	projectID, iid, ok := parseGitLabPullRequestID(pr.PullRequestID)
+	if !ok {
+		return ErrNotHosted
+	}
+
+	reviewerIDs := []int64{}
+	for _, userID := range pr.AssignedReviewers {
+		ext, err := c.users.ExternalUserID(ctx, ProviderGitLab, userID)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		id, err := strconv.ParseInt(ext, 10, 64)
+		if err != nil {
+			continue
+		}
+		reviewerIDs = append(reviewerIDs, id)
+	}
+	if len(reviewerIDs) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(map[string]any{"reviewer_ids": reviewerIDs})
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/api/v4/projects/%d/merge_requests/%d", c.baseURL, projectID, iid)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("PRIVATE-TOKEN", c.token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	switch {
+	case resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("gitlab: %s: %s", resp.Status, msg)
+	default:
+		return Permanent(fmt.Errorf("gitlab: %s: %s", resp.Status, msg))
+	}
+}
+
+func parseGitLabPullRequestID(prID string) (projectID, iid int64, ok bool) {
+	rest, found := strings.CutPrefix(prID, ProviderGitLab+":")
+	if !found {
+		return 0, 0, false
+	}
+	project, number, found := strings.Cut(rest, "!")
+	if !found {
+		return 0, 0, false
+	}
+	projectID, err := strconv.ParseInt(project, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	iid, err = strconv.ParseInt(number, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return projectID, iid, true
+}
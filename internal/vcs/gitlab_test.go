@@ -0,0 +1,64 @@
+package vcs
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestVerifyGitLabToken(t *testing.T) {
+	h := http.Header{}
+	h.Set("X-Gitlab-Token", "s3cret")
+
+	if err := VerifyGitLabToken(h, "s3cret"); err != nil {
+		t.Fatalf("valid token rejected: %v", err)
+	}
+	if err := VerifyGitLabToken(h, "other"); err != ErrInvalidToken {
+		t.Fatalf("wrong token accepted: %v", err)
+	}
+	if err := VerifyGitLabToken(http.Header{}, ""); err != ErrInvalidToken {
+		t.Fatalf("empty secret must never match: %v", err)
+	}
+}
+
+func TestParseGitLabEvent(t *testing.T) {
+	body := []byte(`{
+		"object_kind": "merge_request",
+		"project": {"id": 15},
+		"object_attributes": {"iid": 7, "title": "Add feature X", "author_id": 51, "action": "merge"}
+	}`)
+	h := http.Header{}
+	h.Set("X-Gitlab-Event-UUID", "uuid-1")
+
+	ev, err := ParseGitLabEvent(h, body)
+	if err != nil {
+		t.Fatalf("ParseGitLabEvent: %v", err)
+	}
+	if ev.Kind != EventMerged || ev.PullRequestID != "gitlab:15!7" || ev.AuthorExternalID != "51" || ev.DeliveryID != "uuid-1" {
+		t.Fatalf("unexpected event: %+v", ev)
+	}
+
+	h.Set("Idempotency-Key", "key-1")
+	ev, _ = ParseGitLabEvent(h, body)
+	if ev.DeliveryID != "key-1" {
+		t.Fatalf("Idempotency-Key should take precedence, got %q", ev.DeliveryID)
+	}
+
+	ev, err = ParseGitLabEvent(h, []byte(`{
+		"object_kind": "merge_request",
+		"project": {"id": 15},
+		"object_attributes": {"iid": 7, "action": "update", "draft": false},
+		"changes": {"draft": {"previous": true, "current": false}}
+	}`))
+	if err != nil || ev.Kind != EventReady {
+		t.Fatalf("leaving draft should mark the PR ready: %+v, %v", ev, err)
+	}
+
+	ev, err = ParseGitLabEvent(h, []byte(`{"object_kind": "push"}`))
+	if err != nil || ev.Kind != EventIgnored {
+		t.Fatalf("push event should be ignored: %+v, %v", ev, err)
+	}
+
+	if _, err := ParseGitLabEvent(h, []byte(`{"object_kind": "merge_request"}`)); err != ErrInvalidPayload {
+		t.Fatalf("merge request without project should be rejected: %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+package vcs
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+)
+
+// ReviewRequester pushes a PR's assigned reviewers to the code host.
+type ReviewRequester interface {
+	Provider() string
+	RequestReviewers(ctx context.Context, pr models.PullRequest) error
+}
+
+// ErrNotHosted means the PR did not come from the requester's host and
+// there is nothing to push.
+var ErrNotHosted = errors.New("pull request is not hosted by this provider")
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not worth retrying, e.g. a 4xx from the host.
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+func IsPermanent(err error) bool {
+	var pe *permanentError
+	return errors.As(err, &pe)
+}
+
+type DeadLetterStore interface {
+	SaveDeadLetter(ctx context.Context, provider, prID string, reviewers []string, attempts int, lastErr string) error
+}
+
+type DispatcherConfig struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	QueueSize   int
+}
+
+func DefaultDispatcherConfig() DispatcherConfig {
+	return DispatcherConfig{
+		MaxAttempts: 5,
+		BaseDelay:   time.Second,
+		MaxDelay:    time.Minute,
+		QueueSize:   256,
+	}
+}
+
+// Dispatcher delivers reviewer updates in the background, retrying with
+// exponential backoff while the host is unavailable and dead-lettering
+// updates that still fail after MaxAttempts.
+type Dispatcher struct {
+	requester ReviewRequester
+	dead      DeadLetterStore
+	cfg       DispatcherConfig
+	queue     chan models.PullRequest
+	overflow  sync.WaitGroup
+}
+
+func NewDispatcher(requester ReviewRequester, dead DeadLetterStore, cfg DispatcherConfig) *Dispatcher {
+	return &Dispatcher{
+		requester: requester,
+		dead:      dead,
+		cfg:       cfg,
+		queue:     make(chan models.PullRequest, cfg.QueueSize),
+	}
+}
+
+// PublishReviewers implements service.ReviewerPublisher. When the queue is
+// full the update goes to the dead-letter table, written in the background
+// so the caller never waits on the database.
+func (d *Dispatcher) PublishReviewers(pr models.PullRequest) {
+	select {
+	case d.queue <- pr:
+	default:
+		d.overflow.Add(1)
+		go func() {
+			defer d.overflow.Done()
+			d.deadLetter(context.Background(), pr, 0, errors.New("outbound queue full"))
+		}()
+	}
+}
+
+// Dispatchers fans updates out to one dispatcher per code host; each one
+// skips the PRs its host does not serve.
+type Dispatchers []*Dispatcher
+
+func (ds Dispatchers) PublishReviewers(pr models.PullRequest) {
+	for _, d := range ds {
+		d.PublishReviewers(pr)
+	}
+}
+
+// Run delivers queued updates until ctx is cancelled. Updates still queued
+// then are dead-lettered so a restart does not lose them silently.
+func (d *Dispatcher) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			d.drain()
+			d.overflow.Wait()
+			return
+		case pr := <-d.queue:
+			d.deliver(ctx, pr)
+		}
+	}
+}
+
+func (d *Dispatcher) drain() {
+	for {
+		select {
+		case pr := <-d.queue:
+			d.deadLetter(context.Background(), pr, 0, errors.New("dispatcher stopped"))
+		default:
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, pr models.PullRequest) {
+	delay := d.cfg.BaseDelay
+	var err error
+	for attempt := 1; attempt <= d.cfg.MaxAttempts; attempt++ {
+		err = d.requester.RequestReviewers(ctx, pr)
+		if err == nil || errors.Is(err, ErrNotHosted) {
+			return
+		}
+		if IsPermanent(err) || attempt == d.cfg.MaxAttempts {
+			d.deadLetter(ctx, pr, attempt, err)
+			return
+		}
+		slog.WarnContext(ctx, "vcs request reviewers failed", "provider", d.requester.Provider(), "pull_request_id", pr.PullRequestID, "attempt", attempt, "err", err)
+
+		select {
+		case <-ctx.Done():
+			d.deadLetter(context.Background(), pr, attempt, err)
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > d.cfg.MaxDelay {
+			delay = d.cfg.MaxDelay
+		}
+	}
+}
+
+func (d *Dispatcher) deadLetter(ctx context.Context, pr models.PullRequest, attempts int, cause error) {
+	slog.ErrorContext(ctx, "vcs request reviewers gave up", "provider", d.requester.Provider(), "pull_request_id", pr.PullRequestID, "attempts", attempts, "err", cause)
+	if err := d.dead.SaveDeadLetter(ctx, d.requester.Provider(), pr.PullRequestID, pr.AssignedReviewers, attempts, cause.Error()); err != nil {
+		slog.ErrorContext(ctx, "vcs save dead letter failed", "pull_request_id", pr.PullRequestID, "err", err)
+	}
+}
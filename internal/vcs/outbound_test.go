@@ -0,0 +1,207 @@
+package vcs
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+	"github.com/Guardian1221/prsvc/internal/vcs/vcstest"
+)
+
+type mapResolver map[string]string
+
+func (m mapResolver) ExternalUserID(_ context.Context, _, userID string) (string, error) {
+	id, ok := m[userID]
+	if !ok {
+		return "", sql.ErrNoRows
+	}
+	return id, nil
+}
+
+type deadLetter struct {
+	prID     string
+	attempts int
+}
+
+type memDeadLetters struct {
+	mu      sync.Mutex
+	letters []deadLetter
+}
+
+func (m *memDeadLetters) SaveDeadLetter(_ context.Context, _, prID string, _ []string, attempts int, _ string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.letters = append(m.letters, deadLetter{prID: prID, attempts: attempts})
+	return nil
+}
+
+func testDispatcherConfig() DispatcherConfig {
+	return DispatcherConfig{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, QueueSize: 4}
+}
+
+func TestGitLabClientRequestReviewers(t *testing.T) {
+	fake := vcstest.NewFakeGitLab()
+	defer fake.Close()
+
+	client := NewGitLabClient(fake.URL, "tok", mapResolver{"u1": "101", "u2": "102"})
+	pr := models.PullRequest{PullRequestID: "gitlab:15!7", AssignedReviewers: []string{"u1", "u2", "unmapped"}}
+	if err := client.RequestReviewers(context.Background(), pr); err != nil {
+		t.Fatalf("RequestReviewers: %v", err)
+	}
+
+	reqs := fake.Requests()
+	if len(reqs) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(reqs))
+	}
+	got := reqs[0]
+	if got.Method != http.MethodPut || got.Path != "/api/v4/projects/15/merge_requests/7" || got.Token != "tok" {
+		t.Fatalf("unexpected request: %+v", got)
+	}
+	if len(got.ReviewerIDs) != 2 || got.ReviewerIDs[0] != 101 || got.ReviewerIDs[1] != 102 {
+		t.Fatalf("unexpected reviewer ids: %v", got.ReviewerIDs)
+	}
+
+	if err := client.RequestReviewers(context.Background(), models.PullRequest{PullRequestID: "gitlab:15!8", AssignedReviewers: []string{"unmapped"}}); err != nil {
+		t.Fatalf("RequestReviewers: %v", err)
+	}
+	if n := len(fake.Requests()); n != 1 {
+		t.Fatalf("no mapped reviewer must not clear the MR's reviewers, got %d requests", n)
+	}
+
+	if err := client.RequestReviewers(context.Background(), models.PullRequest{PullRequestID: "pr1"}); err != ErrNotHosted {
+		t.Fatalf("non-GitLab PR should be skipped, got %v", err)
+	}
+}
+
+func TestGitHubClientRequestReviewers(t *testing.T) {
+	fake := vcstest.NewFakeGitHub()
+	defer fake.Close()
+
+	client := NewGitHubClient(fake.URL, "tok", mapResolver{"u1": "alice", "u2": "bob"})
+	pr := models.PullRequest{PullRequestID: GitHubPullRequestID("acme", "api", 12), AssignedReviewers: []string{"u1", "unmapped", "u2"}}
+	if err := client.RequestReviewers(context.Background(), pr); err != nil {
+		t.Fatalf("RequestReviewers: %v", err)
+	}
+
+	reqs := fake.Requests()
+	if len(reqs) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(reqs))
+	}
+	got := reqs[0]
+	if got.Method != http.MethodPost || got.Path != "/repos/acme/api/pulls/12/requested_reviewers" || got.Token != "tok" {
+		t.Fatalf("unexpected request: %+v", got)
+	}
+	if len(got.Reviewers) != 2 || got.Reviewers[0] != "alice" || got.Reviewers[1] != "bob" {
+		t.Fatalf("unexpected reviewers: %v", got.Reviewers)
+	}
+
+	pr.AssignedReviewers = []string{"unmapped"}
+	if err := client.RequestReviewers(context.Background(), pr); err != nil || len(fake.Requests()) != 1 {
+		t.Fatalf("no mapped reviewer should send nothing: %v, %d requests", err, len(fake.Requests()))
+	}
+
+	fake.FailNext(1, http.StatusUnprocessableEntity)
+	pr.AssignedReviewers = []string{"u1"}
+	if err := client.RequestReviewers(context.Background(), pr); !IsPermanent(err) {
+		t.Fatalf("422 should be permanent, got %v", err)
+	}
+
+	for _, id := range []string{"gitlab:15!7", "github:acme#12", "github:acme/api/x#12", "github:acme/api#x"} {
+		if err := client.RequestReviewers(context.Background(), models.PullRequest{PullRequestID: id}); err != ErrNotHosted {
+			t.Fatalf("%s should be skipped, got %v", id, err)
+		}
+	}
+}
+
+func TestDispatcherRetriesThenDelivers(t *testing.T) {
+	fake := vcstest.NewFakeGitLab()
+	defer fake.Close()
+	fake.FailNext(2, http.StatusServiceUnavailable)
+
+	dead := &memDeadLetters{}
+	d := NewDispatcher(NewGitLabClient(fake.URL, "tok", mapResolver{"u1": "101"}), dead, testDispatcherConfig())
+	d.deliver(context.Background(), models.PullRequest{PullRequestID: "gitlab:1!1", AssignedReviewers: []string{"u1"}})
+
+	if n := len(fake.Requests()); n != 3 {
+		t.Fatalf("expected 3 attempts, got %d", n)
+	}
+	if len(dead.letters) != 0 {
+		t.Fatalf("delivered update must not be dead-lettered: %+v", dead.letters)
+	}
+}
+
+func TestDispatcherDeadLetters(t *testing.T) {
+	fake := vcstest.NewFakeGitLab()
+	defer fake.Close()
+
+	dead := &memDeadLetters{}
+	d := NewDispatcher(NewGitLabClient(fake.URL, "tok", mapResolver{"u1": "101"}), dead, testDispatcherConfig())
+
+	fake.FailNext(10, http.StatusBadGateway)
+	d.deliver(context.Background(), models.PullRequest{PullRequestID: "gitlab:1!1", AssignedReviewers: []string{"u1"}})
+	if len(dead.letters) != 1 || dead.letters[0].attempts != 3 {
+		t.Fatalf("expected dead letter after 3 attempts, got %+v", dead.letters)
+	}
+
+	fake.FailNext(1, http.StatusForbidden)
+	d.deliver(context.Background(), models.PullRequest{PullRequestID: "gitlab:1!2", AssignedReviewers: []string{"u1"}})
+	if len(dead.letters) != 2 || dead.letters[1].attempts != 1 {
+		t.Fatalf("4xx should be dead-lettered without retries, got %+v", dead.letters)
+	}
+}
+
+func TestDispatcherDeadLettersQueueOnStop(t *testing.T) {
+	dead := &memDeadLetters{}
+	d := NewDispatcher(NewGitLabClient("http://127.0.0.1:1", "tok", mapResolver{"u1": "101"}), dead, testDispatcherConfig())
+	d.PublishReviewers(models.PullRequest{PullRequestID: "gitlab:1!1", AssignedReviewers: []string{"u1"}})
+	d.PublishReviewers(models.PullRequest{PullRequestID: "gitlab:1!2", AssignedReviewers: []string{"u1"}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	d.Run(ctx)
+
+	if len(dead.letters) != 2 {
+		t.Fatalf("expected both queued updates dead-lettered, got %+v", dead.letters)
+	}
+}
+
+type blockingDeadLetters struct {
+	release chan struct{}
+	memDeadLetters
+}
+
+func (b *blockingDeadLetters) SaveDeadLetter(ctx context.Context, provider, prID string, reviewers []string, attempts int, lastErr string) error {
+	<-b.release
+	return b.memDeadLetters.SaveDeadLetter(ctx, provider, prID, reviewers, attempts, lastErr)
+}
+
+func TestDispatcherPublishDoesNotBlockWhenFull(t *testing.T) {
+	dead := &blockingDeadLetters{release: make(chan struct{})}
+	cfg := testDispatcherConfig()
+	cfg.QueueSize = 1
+	d := NewDispatcher(NewGitLabClient("http://127.0.0.1:1", "tok", mapResolver{"u1": "101"}), dead, cfg)
+
+	published := make(chan struct{})
+	go func() {
+		d.PublishReviewers(models.PullRequest{PullRequestID: "gitlab:1!1", AssignedReviewers: []string{"u1"}})
+		d.PublishReviewers(models.PullRequest{PullRequestID: "gitlab:1!2", AssignedReviewers: []string{"u1"}})
+		close(published)
+	}()
+	select {
+	case <-published:
+	case <-time.After(time.Second):
+		t.Fatal("PublishReviewers blocked on the dead-letter store")
+	}
+
+	close(dead.release)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	d.Run(ctx)
+	if len(dead.letters) != 2 {
+		t.Fatalf("expected the overflow and the queued update dead-lettered, got %+v", dead.letters)
+	}
+}
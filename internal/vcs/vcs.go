@@ -0,0 +1,152 @@
+// Package vcs turns code host webhooks into prsvc pull request operations.
+//
+// Each provider only parses its own payloads into an Event; the Processor
+// applies the shared rules: external user IDs are resolved through
+// vcs_user_mappings, deliveries are deduplicated through vcs_deliveries, and
+// every operation is safe to replay.
+package vcs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/Guardian1221/prsvc/internal/models"
+	"github.com/Guardian1221/prsvc/internal/repo"
+	"github.com/Guardian1221/prsvc/internal/service"
+)
+
+const (
+	ProviderGitLab = "gitlab"
+	ProviderGitHub = "github"
+)
+
+// KnownProvider reports whether provider is accepted for user mappings.
+func KnownProvider(provider string) bool {
+	switch provider {
+	case ProviderGitLab, ProviderGitHub:
+		return true
+	}
+	return false
+}
+
+type EventKind string
+
+const (
+	EventOpened   EventKind = "opened"
+	EventReopened EventKind = "reopened"
+	EventReady    EventKind = "ready"
+	EventMerged   EventKind = "merged"
+	EventClosed   EventKind = "closed"
+	EventIgnored  EventKind = "ignored"
+)
+
+type Event struct {
+	Provider         string
+	DeliveryID       string
+	Kind             EventKind
+	PullRequestID    string
+	Title            string
+	AuthorExternalID string
+	Draft            bool
+}
+
+var ErrUserNotMapped = errors.New("external user not mapped")
+
+type Outcome string
+
+const (
+	OutcomeApplied   Outcome = "applied"
+	OutcomeDuplicate Outcome = "duplicate"
+	OutcomeIgnored   Outcome = "ignored"
+)
+
+type Processor struct {
+	svc *service.Service
+}
+
+func NewProcessor(svc *service.Service) *Processor {
+	return &Processor{svc: svc}
+}
+
+// Apply runs ev against the service. The delivery is claimed before it is
+// applied, so of two concurrent redeliveries only one goes through; the
+// claim is released again when applying fails, so the host can retry.
+func (p *Processor) Apply(ctx context.Context, ev Event) (Outcome, *models.PullRequest, error) {
+	if ev.DeliveryID != "" {
+		claimed, err := p.svc.ClaimDelivery(ctx, ev.Provider, ev.DeliveryID)
+		if err != nil {
+			return "", nil, err
+		}
+		if !claimed {
+			return OutcomeDuplicate, nil, nil
+		}
+	}
+
+	outcome, pr, err := p.apply(ctx, ev)
+	if err != nil {
+		if ev.DeliveryID != "" {
+			if rerr := p.svc.ReleaseDelivery(context.WithoutCancel(ctx), ev.Provider, ev.DeliveryID); rerr != nil {
+				slog.ErrorContext(ctx, "vcs release delivery failed", "provider", ev.Provider, "delivery_id", ev.DeliveryID, "err", rerr)
+			}
+		}
+		return "", nil, err
+	}
+	return outcome, pr, nil
+}
+
+func (p *Processor) apply(ctx context.Context, ev Event) (Outcome, *models.PullRequest, error) {
+	var pr *models.PullRequest
+	var err error
+	switch ev.Kind {
+	case EventOpened:
+		return p.open(ctx, ev)
+	case EventReopened:
+		pr, _, err = p.svc.ReopenPullRequest(ctx, ev.PullRequestID)
+		if err == sql.ErrNoRows {
+			// The MR was opened before the webhook was installed.
+			return p.open(ctx, ev)
+		}
+	case EventReady:
+		pr, _, err = p.svc.MarkReady(ctx, ev.PullRequestID)
+	case EventMerged:
+		pr, err = p.svc.RecordExternalMerge(ctx, ev.PullRequestID)
+	case EventClosed:
+		pr, err = p.svc.ClosePullRequest(ctx, ev.PullRequestID)
+	default:
+		return OutcomeIgnored, nil, nil
+	}
+
+	// Unknown PRs and transitions that already happened are replays or
+	// predate the webhook; neither is worth a redelivery.
+	if err == sql.ErrNoRows || err == service.ErrInvalidTransition {
+		return OutcomeIgnored, nil, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return OutcomeApplied, pr, nil
+}
+
+func (p *Processor) open(ctx context.Context, ev Event) (Outcome, *models.PullRequest, error) {
+	authorID, err := p.svc.ResolveExternalUser(ctx, ev.Provider, ev.AuthorExternalID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil, ErrUserNotMapped
+		}
+		return "", nil, err
+	}
+	pr, _, err := p.svc.CreatePullRequest(ctx, models.PullRequest{
+		PullRequestID:   ev.PullRequestID,
+		PullRequestName: ev.Title,
+		AuthorID:        authorID,
+	}, service.CreateOptions{Draft: ev.Draft})
+	if err == repo.ErrPRExists {
+		return OutcomeIgnored, nil, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return OutcomeApplied, pr, nil
+}
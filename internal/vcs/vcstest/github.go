@@ -0,0 +1,74 @@
+package vcstest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// FakeGitHub answers review requests like the GitHub REST API and records
+// every request it receives.
+type FakeGitHub struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []Request
+	failures int
+	status   int
+}
+
+func NewFakeGitHub() *FakeGitHub {
+	f := &FakeGitHub{}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serve))
+	return f
+}
+
+// FailNext makes the next n requests answer with status.
+func (f *FakeGitHub) FailNext(n, status int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures = n
+	f.status = status
+}
+
+func (f *FakeGitHub) Requests() []Request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Request(nil), f.requests...)
+}
+
+func (f *FakeGitHub) serve(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Reviewers []string `json:"reviewers"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	f.mu.Lock()
+	f.requests = append(f.requests, Request{
+		Method:    r.Method,
+		Path:      r.URL.Path,
+		Token:     strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "),
+		Reviewers: body.Reviewers,
+	})
+	fail := f.failures > 0
+	status := f.status
+	if fail {
+		f.failures--
+	}
+	f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if fail {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"message":"unavailable"}`))
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	users := make([]map[string]string, 0, len(body.Reviewers))
+	for _, login := range body.Reviewers {
+		users = append(users, map[string]string{"login": login})
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"requested_reviewers": users})
+}
@@ -0,0 +1,79 @@
+// Package vcstest provides fake code hosts for exercising outbound VCS
+// adapters without network access.
+package vcstest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+)
+
+type Request struct {
+	Method      string
+	Path        string
+	Token       string
+	ReviewerIDs []int64
+	// Reviewers holds the logins of a GitHub review request.
+	Reviewers []string
+}
+
+// FakeGitLab answers merge request updates like the GitLab REST API and
+// records every request it receives.
+type FakeGitLab struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []Request
+	failures int
+	status   int
+}
+
+func NewFakeGitLab() *FakeGitLab {
+	f := &FakeGitLab{}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serve))
+	return f
+}
+
+// FailNext makes the next n requests answer with status.
+func (f *FakeGitLab) FailNext(n, status int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures = n
+	f.status = status
+}
+
+func (f *FakeGitLab) Requests() []Request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Request(nil), f.requests...)
+}
+
+func (f *FakeGitLab) serve(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		ReviewerIDs []int64 `json:"reviewer_ids"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	f.mu.Lock()
+	f.requests = append(f.requests, Request{
+		Method:      r.Method,
+		Path:        r.URL.Path,
+		Token:       r.Header.Get("PRIVATE-TOKEN"),
+		ReviewerIDs: body.ReviewerIDs,
+	})
+	fail := f.failures > 0
+	status := f.status
+	if fail {
+		f.failures--
+	}
+	f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if fail {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"message":"unavailable"}`))
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"reviewers": body.ReviewerIDs})
+}
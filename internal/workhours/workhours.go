@@ -0,0 +1,164 @@
+// Package workhours models a user's weekly working hours in their own time
+// zone and measures durations in working time only.
+package workhours
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Days is a set of weekdays, bit i standing for time.Weekday(i).
+type Days uint8
+
+// Weekdays is Monday to Friday.
+const Weekdays Days = 1<<time.Monday | 1<<time.Tuesday | 1<<time.Wednesday | 1<<time.Thursday | 1<<time.Friday
+
+func (d Days) Has(w time.Weekday) bool {
+	return d&(1<<w) != 0
+}
+
+var dayNames = [7]string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+
+// ParseDays accepts three-letter day names such as "MON".
+func ParseDays(names []string) (Days, error) {
+	var d Days
+	for _, n := range names {
+		found := false
+		for i, dn := range dayNames {
+			if strings.EqualFold(n, dn) {
+				d |= 1 << i
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("unknown day %q", n)
+		}
+	}
+	return d, nil
+}
+
+func (d Days) Names() []string {
+	names := []string{}
+	for i, dn := range dayNames {
+		if d.Has(time.Weekday(i)) {
+			names = append(names, dn)
+		}
+	}
+	return names
+}
+
+// ParseClock turns "HH:MM" into minutes since midnight; "24:00" is allowed
+// as an end of day.
+func ParseClock(s string) (int, error) {
+	var h, m int
+	if _, err := fmt.Sscanf(s, "%d:%d", &h, &m); err != nil || h < 0 || m < 0 || m > 59 || h*60+m > 24*60 {
+		return 0, fmt.Errorf("invalid time of day %q", s)
+	}
+	return h*60 + m, nil
+}
+
+func FormatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+var ErrInvalidCalendar = errors.New("working hours must start before they end")
+
+// Calendar is a weekly working window [Start, End) in minutes since
+// midnight, on Days, in Location.
+type Calendar struct {
+	Location *time.Location
+	Start    int
+	End      int
+	Days     Days
+}
+
+func New(tz string, start, end int, days Days) (Calendar, error) {
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return Calendar{}, err
+	}
+	if start < 0 || end > 24*60 || start >= end {
+		return Calendar{}, ErrInvalidCalendar
+	}
+	return Calendar{Location: loc, Start: start, End: end, Days: days}, nil
+}
+
+// Contains reports whether t falls inside working hours.
+func (c Calendar) Contains(t time.Time) bool {
+	lt := t.In(c.Location)
+	m := lt.Hour()*60 + lt.Minute()
+	return c.Days.Has(lt.Weekday()) && m >= c.Start && m < c.End
+}
+
+// maxScanDays bounds the day-by-day scans so a calendar that never works
+// cannot loop forever.
+const maxScanDays = 3660
+
+func (c Calendar) works() bool {
+	return c.Days != 0 && c.Start < c.End
+}
+
+// window returns the working window of the local day containing t.
+func (c Calendar) window(day time.Time) (time.Time, time.Time) {
+	y, m, d := day.Date()
+	return time.Date(y, m, d, 0, c.Start, 0, 0, c.Location), time.Date(y, m, d, 0, c.End, 0, 0, c.Location)
+}
+
+func nextDay(day time.Time) time.Time {
+	y, m, d := day.Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, day.Location())
+}
+
+// WorkingDuration is the working time between from and to.
+func (c Calendar) WorkingDuration(from, to time.Time) time.Duration {
+	if !to.After(from) || !c.works() {
+		return 0
+	}
+	var total time.Duration
+	day := from.In(c.Location)
+	for i := 0; i < maxScanDays && day.Before(to); i++ {
+		if c.Days.Has(day.Weekday()) {
+			ws, we := c.window(day)
+			if ws.Before(from) {
+				ws = from
+			}
+			if we.After(to) {
+				we = to
+			}
+			if we.After(ws) {
+				total += we.Sub(ws)
+			}
+		}
+		day = nextDay(day)
+	}
+	return total
+}
+
+// AddWorking returns the instant at which d of working time has passed
+// since from. A calendar without any working time counts wall-clock time.
+func (c Calendar) AddWorking(from time.Time, d time.Duration) time.Time {
+	if !c.works() {
+		return from.Add(d)
+	}
+	day := from.In(c.Location)
+	for i := 0; i < maxScanDays; i++ {
+		if c.Days.Has(day.Weekday()) {
+			ws, we := c.window(day)
+			if ws.Before(from) {
+				ws = from
+			}
+			if we.After(ws) {
+				avail := we.Sub(ws)
+				if d <= avail {
+					return ws.Add(d)
+				}
+				d -= avail
+			}
+		}
+		day = nextDay(day)
+	}
+	return from.Add(d)
+}
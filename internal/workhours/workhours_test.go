@@ -0,0 +1,87 @@
+package workhours
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func mustCalendar(t *testing.T, tz string) Calendar {
+	t.Helper()
+	c, err := New(tz, 9*60, 18*60, Weekdays)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return c
+}
+
+func TestContains(t *testing.T) {
+	c := mustCalendar(t, "Europe/Moscow")
+
+	// 2026-10-19 is a Monday; 07:00 UTC is 10:00 in Moscow.
+	if !c.Contains(time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC)) {
+		t.Error("Monday 10:00 local should be working time")
+	}
+	if c.Contains(time.Date(2026, 10, 19, 15, 30, 0, 0, time.UTC)) {
+		t.Error("Monday 18:30 local should be after hours")
+	}
+	if c.Contains(time.Date(2026, 10, 18, 7, 0, 0, 0, time.UTC)) {
+		t.Error("Sunday should not be working time")
+	}
+}
+
+func TestWorkingDuration(t *testing.T) {
+	c := mustCalendar(t, "UTC")
+
+	// Friday 17:00 to Monday 10:00 is one hour on Friday and one on Monday.
+	from := time.Date(2026, 10, 23, 17, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 26, 10, 0, 0, 0, time.UTC)
+	if got := c.WorkingDuration(from, to); got != 2*time.Hour {
+		t.Fatalf("WorkingDuration = %v, want 2h", got)
+	}
+	if got := c.WorkingDuration(to, from); got != 0 {
+		t.Fatalf("reversed interval should be 0, got %v", got)
+	}
+}
+
+func TestAddWorking(t *testing.T) {
+	c := mustCalendar(t, "UTC")
+
+	// A working day of SLA assigned Friday 17:00 ends Monday 17:00.
+	from := time.Date(2026, 10, 23, 17, 0, 0, 0, time.UTC)
+	want := time.Date(2026, 10, 26, 17, 0, 0, 0, time.UTC)
+	if got := c.AddWorking(from, 9*time.Hour); !got.Equal(want) {
+		t.Fatalf("AddWorking = %v, want %v", got, want)
+	}
+
+	// Assigned at night, the clock starts at 09:00.
+	from = time.Date(2026, 10, 20, 3, 0, 0, 0, time.UTC)
+	want = time.Date(2026, 10, 20, 10, 0, 0, 0, time.UTC)
+	if got := c.AddWorking(from, time.Hour); !got.Equal(want) {
+		t.Fatalf("AddWorking = %v, want %v", got, want)
+	}
+}
+
+func TestParse(t *testing.T) {
+	d, err := ParseDays([]string{"mon", "WED", "Sat"})
+	if err != nil {
+		t.Fatalf("ParseDays: %v", err)
+	}
+	if got := d.Names(); !reflect.DeepEqual(got, []string{"MON", "WED", "SAT"}) {
+		t.Fatalf("Names = %v", got)
+	}
+	if _, err := ParseDays([]string{"FUNDAY"}); err == nil {
+		t.Fatal("unknown day accepted")
+	}
+
+	m, err := ParseClock("09:30")
+	if err != nil || m != 570 || FormatClock(m) != "09:30" {
+		t.Fatalf("ParseClock(09:30) = %d, %v", m, err)
+	}
+	if _, err := ParseClock("25:00"); err == nil {
+		t.Fatal("25:00 accepted")
+	}
+	if _, err := New("UTC", 18*60, 9*60, Weekdays); err != ErrInvalidCalendar {
+		t.Fatalf("inverted hours accepted: %v", err)
+	}
+}
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_pr_reviewers_pending;
+
+ALTER TABLE teams
+  DROP COLUMN IF EXISTS review_sla_minutes;
+
+ALTER TABLE pr_reviewers
+  DROP COLUMN IF EXISTS reminded_at,
+  DROP COLUMN IF EXISTS first_response_at,
+  DROP COLUMN IF EXISTS assigned_at;
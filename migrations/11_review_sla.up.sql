@@ -0,0 +1,9 @@
+ALTER TABLE pr_reviewers
+  ADD COLUMN assigned_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  ADD COLUMN first_response_at TIMESTAMPTZ NULL,
+  ADD COLUMN reminded_at TIMESTAMPTZ NULL;
+
+ALTER TABLE teams
+  ADD COLUMN review_sla_minutes INT NOT NULL DEFAULT 1440 CHECK (review_sla_minutes > 0);
+
+CREATE INDEX idx_pr_reviewers_pending ON pr_reviewers(assigned_at) WHERE first_response_at IS NULL;
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_pr_reassignments_pr;
+
+DROP TABLE IF EXISTS pr_reassignments;
+
+ALTER TABLE teams
+  DROP COLUMN IF EXISTS max_auto_reassignments,
+  DROP COLUMN IF EXISTS escalate_after_minutes,
+  DROP COLUMN IF EXISTS auto_escalate;
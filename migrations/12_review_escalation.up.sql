@@ -0,0 +1,15 @@
+ALTER TABLE teams
+  ADD COLUMN auto_escalate BOOLEAN NOT NULL DEFAULT FALSE,
+  ADD COLUMN escalate_after_minutes INT NOT NULL DEFAULT 2880 CHECK (escalate_after_minutes > 0),
+  ADD COLUMN max_auto_reassignments INT NOT NULL DEFAULT 1 CHECK (max_auto_reassignments >= 0);
+
+CREATE TABLE pr_reassignments (
+  id BIGSERIAL PRIMARY KEY,
+  pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+  old_user_id TEXT NOT NULL,
+  new_user_id TEXT NOT NULL,
+  reason TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_pr_reassignments_pr ON pr_reassignments(pull_request_id, reason);
@@ -0,0 +1,6 @@
+ALTER TABLE users
+  DROP CONSTRAINT IF EXISTS chk_users_working_hours,
+  DROP COLUMN IF EXISTS work_days,
+  DROP COLUMN IF EXISTS work_end_minute,
+  DROP COLUMN IF EXISTS work_start_minute,
+  DROP COLUMN IF EXISTS time_zone;
@@ -0,0 +1,9 @@
+ALTER TABLE users
+  ADD COLUMN time_zone TEXT NOT NULL DEFAULT 'UTC',
+  ADD COLUMN work_start_minute INT NOT NULL DEFAULT 540,
+  ADD COLUMN work_end_minute INT NOT NULL DEFAULT 1080,
+  ADD COLUMN work_days INT NOT NULL DEFAULT 62,
+  ADD CONSTRAINT chk_users_working_hours CHECK (
+    work_start_minute >= 0 AND work_end_minute <= 1440 AND work_start_minute < work_end_minute
+    AND work_days >= 0 AND work_days < 128
+  );
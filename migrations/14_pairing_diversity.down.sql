@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_pr_reviewers_user_assigned;
+
+ALTER TABLE teams
+  DROP COLUMN IF EXISTS pairing_window_days;
@@ -0,0 +1,4 @@
+ALTER TABLE teams
+  ADD COLUMN pairing_window_days INT NOT NULL DEFAULT 30 CHECK (pairing_window_days >= 0);
+
+CREATE INDEX idx_pr_reviewers_user_assigned ON pr_reviewers(user_id, assigned_at);
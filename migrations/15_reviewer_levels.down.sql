@@ -0,0 +1,8 @@
+ALTER TABLE teams
+  DROP COLUMN IF EXISTS required_level_count,
+  DROP COLUMN IF EXISTS required_level;
+
+ALTER TABLE users
+  DROP COLUMN IF EXISTS level;
+
+DROP TYPE IF EXISTS user_level;
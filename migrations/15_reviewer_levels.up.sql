@@ -0,0 +1,8 @@
+CREATE TYPE user_level AS ENUM ('JUNIOR', 'MID', 'SENIOR');
+
+ALTER TABLE users
+  ADD COLUMN level user_level NOT NULL DEFAULT 'MID';
+
+ALTER TABLE teams
+  ADD COLUMN required_level user_level NOT NULL DEFAULT 'SENIOR',
+  ADD COLUMN required_level_count INT NOT NULL DEFAULT 0 CHECK (required_level_count >= 0);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_reviewer_exclusions_author;
+
+DROP TABLE IF EXISTS reviewer_exclusions;
@@ -0,0 +1,10 @@
+CREATE TABLE reviewer_exclusions (
+  team_name TEXT NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
+  author_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+  reviewer_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (team_name, author_id, reviewer_id),
+  CHECK (author_id <> reviewer_id)
+);
+
+CREATE INDEX idx_reviewer_exclusions_author ON reviewer_exclusions(author_id);
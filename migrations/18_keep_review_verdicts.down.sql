@@ -0,0 +1,9 @@
+ALTER TABLE pr_reviews
+  DROP CONSTRAINT IF EXISTS pr_reviews_pull_request_id_fkey,
+  DROP CONSTRAINT IF EXISTS pr_reviews_user_id_fkey;
+
+DELETE FROM pr_reviews r
+WHERE NOT EXISTS (SELECT 1 FROM pr_reviewers rv WHERE rv.pull_request_id = r.pull_request_id AND rv.user_id = r.user_id);
+
+ALTER TABLE pr_reviews
+  ADD CONSTRAINT pr_reviews_pull_request_id_user_id_fkey FOREIGN KEY (pull_request_id, user_id) REFERENCES pr_reviewers(pull_request_id, user_id) ON DELETE CASCADE;
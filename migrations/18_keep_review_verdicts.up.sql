@@ -0,0 +1,4 @@
+ALTER TABLE pr_reviews
+  DROP CONSTRAINT IF EXISTS pr_reviews_pull_request_id_user_id_fkey,
+  ADD CONSTRAINT pr_reviews_pull_request_id_fkey FOREIGN KEY (pull_request_id) REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+  ADD CONSTRAINT pr_reviews_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE RESTRICT;
@@ -0,0 +1,4 @@
+DELETE FROM pr_reviewers WHERE released_at IS NOT NULL;
+
+ALTER TABLE pr_reviewers
+  DROP COLUMN IF EXISTS released_at;
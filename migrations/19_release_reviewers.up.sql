@@ -0,0 +1,2 @@
+ALTER TABLE pr_reviewers
+  ADD COLUMN released_at TIMESTAMPTZ NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE teams
+  ALTER COLUMN review_sla_minutes SET DEFAULT 1440,
+  ALTER COLUMN escalate_after_minutes SET DEFAULT 2880;
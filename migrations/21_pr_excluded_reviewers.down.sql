@@ -0,0 +1 @@
+DROP TABLE IF EXISTS pr_excluded_reviewers;
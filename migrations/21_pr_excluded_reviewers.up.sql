@@ -0,0 +1,5 @@
+CREATE TABLE pr_excluded_reviewers (
+  pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+  user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+  PRIMARY KEY (pull_request_id, user_id)
+);
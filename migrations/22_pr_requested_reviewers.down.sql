@@ -0,0 +1 @@
+DROP TABLE IF EXISTS pr_requested_reviewers;
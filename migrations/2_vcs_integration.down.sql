@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_vcs_user_mappings_user;
+
+DROP TABLE IF EXISTS vcs_deliveries;
+DROP TABLE IF EXISTS vcs_user_mappings;
@@ -0,0 +1,15 @@
+CREATE TABLE vcs_user_mappings (
+  provider TEXT NOT NULL,
+  external_id TEXT NOT NULL,
+  user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+  PRIMARY KEY (provider, external_id)
+);
+
+CREATE TABLE vcs_deliveries (
+  provider TEXT NOT NULL,
+  delivery_id TEXT NOT NULL,
+  received_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (provider, delivery_id)
+);
+
+CREATE UNIQUE INDEX idx_vcs_user_mappings_user ON vcs_user_mappings(provider, user_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_vcs_dead_letters_pr;
+
+DROP TABLE IF EXISTS vcs_dead_letters;
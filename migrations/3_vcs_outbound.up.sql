@@ -0,0 +1,11 @@
+CREATE TABLE vcs_dead_letters (
+  id BIGSERIAL PRIMARY KEY,
+  provider TEXT NOT NULL,
+  pull_request_id TEXT NOT NULL,
+  reviewers JSONB NOT NULL,
+  attempts INT NOT NULL,
+  last_error TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_vcs_dead_letters_pr ON vcs_dead_letters(pull_request_id);
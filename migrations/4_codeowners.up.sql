@@ -0,0 +1,5 @@
+CREATE TABLE codeowners (
+  repository TEXT PRIMARY KEY,
+  content TEXT NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
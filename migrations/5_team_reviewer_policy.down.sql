@@ -0,0 +1,4 @@
+ALTER TABLE teams
+  DROP CONSTRAINT IF EXISTS chk_teams_reviewers,
+  DROP COLUMN IF EXISTS max_reviewers,
+  DROP COLUMN IF EXISTS min_reviewers;
@@ -0,0 +1,4 @@
+ALTER TABLE teams
+  ADD COLUMN min_reviewers INT NOT NULL DEFAULT 1,
+  ADD COLUMN max_reviewers INT NOT NULL DEFAULT 2,
+  ADD CONSTRAINT chk_teams_reviewers CHECK (min_reviewers >= 0 AND max_reviewers >= 1 AND min_reviewers <= max_reviewers);
@@ -0,0 +1,7 @@
+CREATE TABLE team_fallbacks (
+  team_name TEXT NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
+  fallback_team TEXT NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
+  priority INT NOT NULL,
+  PRIMARY KEY (team_name, fallback_team),
+  CHECK (team_name <> fallback_team)
+);
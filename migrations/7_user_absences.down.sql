@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_user_absences_pending;
+DROP INDEX IF EXISTS idx_user_absences_user;
+
+DROP TABLE IF EXISTS user_absences;
@@ -0,0 +1,14 @@
+CREATE TABLE user_absences (
+  id BIGSERIAL PRIMARY KEY,
+  user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+  starts_at TIMESTAMPTZ NOT NULL,
+  ends_at TIMESTAMPTZ NOT NULL,
+  reason TEXT NOT NULL DEFAULT '',
+  auto_reassign BOOLEAN NOT NULL DEFAULT FALSE,
+  reassigned_at TIMESTAMPTZ NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX idx_user_absences_user ON user_absences(user_id, starts_at, ends_at);
+CREATE INDEX idx_user_absences_pending ON user_absences(starts_at) WHERE auto_reassign AND reassigned_at IS NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE users
+  ADD COLUMN max_open_reviews INT NULL CHECK (max_open_reviews >= 0);
@@ -0,0 +1,14 @@
+CREATE TYPE review_verdict AS ENUM ('APPROVED','CHANGES_REQUESTED');
+
+CREATE TABLE pr_reviews (
+  pull_request_id TEXT NOT NULL,
+  user_id TEXT NOT NULL,
+  verdict review_verdict NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (pull_request_id, user_id),
+  FOREIGN KEY (pull_request_id, user_id) REFERENCES pr_reviewers(pull_request_id, user_id) ON DELETE CASCADE
+);
+
+ALTER TABLE teams
+  ADD COLUMN required_approvals INT NOT NULL DEFAULT 0 CHECK (required_approvals >= 0);